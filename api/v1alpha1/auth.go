@@ -4,24 +4,308 @@ import corev1 "k8s.io/api/core/v1"
 
 // Authentication configuration for Guacamole.
 // At least one method has to be configured.
+// +kubebuilder:validation:XValidation:rule="[has(self.postgres), has(self.mysql), has(self.sqlserver)].filter(x, x).size() <= 1",message="only one database may be configured"
 type Auth struct {
 	// +optional
 	Postgres *Postgres `json:"postgres,omitempty"`
 
+	// MySQL or MariaDB authentication.
+	// +optional
+	MySQL *MySQL `json:"mysql,omitempty"`
+
+	// SQL Server authentication.
+	// +optional
+	SQLServer *SQLServer `json:"sqlserver,omitempty"`
+
 	// +optional
 	OIDC *OIDC `json:"oidc,omitempty"`
+
+	// LDAP authentication, e.g. against Active Directory.
+	// +optional
+	LDAP *LDAP `json:"ldap,omitempty"`
+
+	// SAML authentication.
+	// +optional
+	SAML *SAML `json:"saml,omitempty"`
+
+	// CAS authentication.
+	// +optional
+	CAS *CAS `json:"cas,omitempty"`
+
+	// Authentication via a header set by a reverse proxy.
+	// +optional
+	Header *Header `json:"header,omitempty"`
+
+	// RADIUS authentication.
+	// +optional
+	RADIUS *RADIUS `json:"radius,omitempty"`
+
+	// Multi-factor authentication.
+	// +optional
+	MFA *MFA `json:"mfa,omitempty"`
+
+	// Encrypted JSON authentication, e.g. for short-lived links
+	// to a single connection.
+	// +optional
+	JSON *JSON `json:"json,omitempty"`
 }
 
 // Postgres authentication.
 type Postgres struct {
+	ExtensionPriority `json:",inline"`
+
+	Parameter []Parameter `json:"params"`
+}
+
+// MySQL authentication.
+type MySQL struct {
+	ExtensionPriority `json:",inline"`
+
+	Parameter []Parameter `json:"params"`
+}
+
+// SQLServer authentication.
+type SQLServer struct {
+	ExtensionPriority `json:",inline"`
+
 	Parameter []Parameter `json:"params"`
 }
 
 // OIDC authentication.
 type OIDC struct {
+	ExtensionPriority `json:",inline"`
+
 	Parameter []Parameter `json:"params"`
 }
 
+// LDAP authentication. Parameters are the `LDAP_*` variables of the
+// Guacamole image. Certificates of LDAP servers using `ssl` or `starttls`
+// encryption are verified with the CA certificates of `tls.caCertificates`.
+// +kubebuilder:validation:XValidation:rule="self.params.exists(p, p.name == 'LDAP_HOSTNAME')",message="LDAP_HOSTNAME is required"
+// +kubebuilder:validation:XValidation:rule="self.params.exists(p, p.name == 'LDAP_USER_BASE_DN')",message="LDAP_USER_BASE_DN is required"
+type LDAP struct {
+	ExtensionPriority `json:",inline"`
+
+	// +kubebuilder:validation:MaxItems=64
+	Parameter []Parameter `json:"params"`
+}
+
+// SAML authentication. Single sign-on methods (OIDC, SAML and CAS) are
+// ordered after all other methods via the extension priority, unless
+// a priority is set or `extension-priority` is set in `additionalSettings`.
+// +kubebuilder:validation:XValidation:rule="has(self.idpMetadataURL) || has(self.idpURL)",message="one of idpMetadataURL or idpURL is required"
+type SAML struct {
+	ExtensionPriority `json:",inline"`
+
+	// URL of the IdP metadata.
+	// +optional
+	IdPMetadataURL string `json:"idpMetadataURL,omitempty"`
+
+	// URL of the IdP single sign-on service. Not required
+	// if `idpMetadataURL` is set.
+	// +optional
+	IdPURL string `json:"idpURL,omitempty"`
+
+	// Entity ID of Guacamole. Not required if `idpMetadataURL` is set.
+	// +optional
+	EntityID string `json:"entityID,omitempty"`
+
+	// URL of Guacamole the IdP redirects to.
+	// Not required if `idpMetadataURL` is set.
+	// +optional
+	CallbackURL string `json:"callbackURL,omitempty"`
+
+	// Attribute containing the groups of a user.
+	// +optional
+	GroupAttribute string `json:"groupAttribute,omitempty"`
+
+	// Require signed and encrypted responses. Defaults to true.
+	// +optional
+	Strict *bool `json:"strict,omitempty"`
+
+	// Compress requests sent to the IdP. Defaults to true.
+	// +optional
+	CompressRequest *bool `json:"compressRequest,omitempty"`
+
+	// Request compressed responses from the IdP. Defaults to true.
+	// +optional
+	CompressResponse *bool `json:"compressResponse,omitempty"`
+}
+
+// CAS authentication.
+type CAS struct {
+	ExtensionPriority `json:",inline"`
+
+	// Authorization endpoint of the CAS server, e.g. `https://cas.example.net/cas`.
+	AuthorizationEndpoint string `json:"authorizationEndpoint"`
+
+	// URL of Guacamole the CAS server redirects to.
+	RedirectURI string `json:"redirectURI"`
+
+	// Private key (PKCS#8, DER) to decrypt ClearPass credentials.
+	// +optional
+	ClearPassKeyRef *corev1.SecretKeySelector `json:"clearPassKeyRef,omitempty"`
+
+	// Attribute containing the groups of a user.
+	// +optional
+	GroupAttribute string `json:"groupAttribute,omitempty"`
+
+	// Format of the groups.
+	// +optional
+	// +kubebuilder:validation:Enum=plain;ldap
+	GroupFormat string `json:"groupFormat,omitempty"`
+
+	// Base DN of groups in `ldap` format.
+	// +optional
+	GroupLDAPBaseDN string `json:"groupLDAPBaseDN,omitempty"`
+
+	// Attribute of groups in `ldap` format.
+	// +optional
+	GroupLDAPAttribute string `json:"groupLDAPAttribute,omitempty"`
+}
+
+// Header authentication. The reverse proxy in front of Guacamole
+// has to authenticate users and must remove the header from requests.
+type Header struct {
+	ExtensionPriority `json:",inline"`
+
+	// Name of the header containing the username.
+	// +optional
+	// +kubebuilder:default=REMOTE_USER
+	Name string `json:"name,omitempty"`
+}
+
+// RADIUS authentication.
+type RADIUS struct {
+	ExtensionPriority `json:",inline"`
+
+	// Hostname of the RADIUS server.
+	Hostname string `json:"hostname"`
+
+	// Authentication port of the RADIUS server.
+	// +optional
+	AuthPort *int32 `json:"authPort,omitempty"`
+
+	// Shared secret of the RADIUS server.
+	SharedSecretRef corev1.SecretKeySelector `json:"sharedSecretRef"`
+
+	// Authentication protocol.
+	// +kubebuilder:validation:Enum=pap;chap;mschapv1;mschapv2;eap-md5
+	AuthProtocol string `json:"authProtocol"`
+
+	// Number of retries.
+	// +optional
+	Retries *int32 `json:"retries,omitempty"`
+
+	// Timeout in seconds.
+	// +optional
+	Timeout *int32 `json:"timeout,omitempty"`
+
+	// IP address sent to the RADIUS server as NAS-IP.
+	// +optional
+	NASIP string `json:"nasIP,omitempty"`
+}
+
+// ResetTOTPAnnotation lists users, separated by commas, whose TOTP
+// enrollment is reset. Removed once the enrollments are reset.
+const ResetTOTPAnnotation = "guacamole-operator.github.io/reset-totp"
+
+// MFA configures multi-factor authentication.
+type MFA struct {
+	// TOTP authentication. Enrollment of a user can be reset with the
+	// `guacamole-operator.github.io/reset-totp` annotation.
+	// +optional
+	TOTP *TOTP `json:"totp,omitempty"`
+
+	// Duo authentication.
+	// +optional
+	Duo *Duo `json:"duo,omitempty"`
+}
+
+// TOTP authentication.
+type TOTP struct {
+	ExtensionPriority `json:",inline"`
+
+	// Issuer shown in authenticator apps.
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// Number of digits of a code.
+	// +optional
+	// +kubebuilder:validation:Enum=6;7;8
+	Digits *int32 `json:"digits,omitempty"`
+
+	// Validity of a code in seconds.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Period *int32 `json:"period,omitempty"`
+
+	// Hash algorithm to generate codes.
+	// +optional
+	// +kubebuilder:validation:Enum=sha1;sha256;sha512
+	Mode string `json:"mode,omitempty"`
+}
+
+// Duo authentication using the Universal Prompt.
+type Duo struct {
+	ExtensionPriority `json:",inline"`
+
+	// API hostname of the Duo application.
+	APIHostname string `json:"apiHostname"`
+
+	// Integration key (client ID) of the Duo application.
+	IntegrationKeyRef corev1.SecretKeySelector `json:"integrationKeyRef"`
+
+	// Secret key (client secret) of the Duo application.
+	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
+
+	// URL of Guacamole Duo redirects to. Required by Guacamole 1.6
+	// and later.
+	// +optional
+	RedirectURI string `json:"redirectURI,omitempty"`
+
+	// Application key of at least 40 characters, generated for the
+	// instance. Required by Guacamole versions before 1.6.
+	// +optional
+	ApplicationKeyRef *corev1.SecretKeySelector `json:"applicationKeyRef,omitempty"`
+}
+
+// JSONAuthSecretKey is the key of the secret key in the JSON auth secret.
+const JSONAuthSecretKey = "secret-key"
+
+// JSON authentication. Payloads are signed and encrypted with a shared
+// secret key of 128 bits, encoded as 32 hexadecimal digits. Connections
+// of payloads use the guacd of the instance, guacd pools are not supported.
+type JSON struct {
+	ExtensionPriority `json:",inline"`
+
+	// Secret containing the secret key (`secret-key`). If not set,
+	// a secret with a generated key is created.
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// SecretName returns the name of the secret holding the secret key.
+func (j *JSON) SecretName(instance string) string {
+	if j.SecretRef != nil {
+		return j.SecretRef.Name
+	}
+
+	return "guacamole-json-auth-" + instance
+}
+
+// ExtensionPriority defines the load priority of an extension. Extensions
+// are loaded in ascending order of priority. Extensions without priority
+// are loaded at priority 0, single sign-on methods (OIDC, SAML and CAS)
+// at priority 1, so that the login form is shown with links to the
+// identity providers. Ignored if `extension-priority` is set in
+// `additionalSettings`.
+type ExtensionPriority struct {
+	// Load priority of the extension.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+}
+
 // Parameter for an authentication method.
 type Parameter struct {
 	Name      string                   `json:"name"`
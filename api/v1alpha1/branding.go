@@ -0,0 +1,61 @@
+package v1alpha1
+
+import corev1 "k8s.io/api/core/v1"
+
+// Branding customizes the appearance of the web application. The operator
+// packages the content into an extension, which is updated whenever the
+// referenced content changes. The packaged extension is stored in a
+// ConfigMap and therefore limited to 1 MiB.
+type Branding struct {
+	// Name of the application shown in the title and on the login page.
+	// +optional
+	Title string `json:"title,omitempty"`
+
+	// Logo shown on the login page. The file extension of the key
+	// determines the image type, e.g. `logo.svg`.
+	// +optional
+	Logo *corev1.ConfigMapKeySelector `json:"logo,omitempty"`
+
+	// Background image of the login page. The file extension of the key
+	// determines the image type, e.g. `background.jpg`.
+	// +optional
+	Background *corev1.ConfigMapKeySelector `json:"background,omitempty"`
+
+	// Additional style sheet, e.g. to change colours.
+	// +optional
+	CSS *corev1.ConfigMapKeySelector `json:"css,omitempty"`
+
+	// Translations overriding texts of the web application,
+	// e.g. the login message.
+	// +optional
+	// +listType=map
+	// +listMapKey=language
+	Translations []BrandingTranslation `json:"translations,omitempty"`
+}
+
+// BrandingTranslation references a translation file.
+type BrandingTranslation struct {
+	// Language key, e.g. `en` or `de`.
+	// +kubebuilder:validation:Pattern=`^[a-z]{2}(_[A-Z]{2})?$`
+	Language string `json:"language"`
+
+	// Translation file in JSON format.
+	ConfigMapKeyRef corev1.ConfigMapKeySelector `json:"configMapKeyRef"`
+}
+
+// ConfigMapNames returns the names of the referenced ConfigMaps.
+func (b *Branding) ConfigMapNames() []string {
+	var names []string
+
+	for _, ref := range []*corev1.ConfigMapKeySelector{b.Logo, b.Background, b.CSS} {
+		if ref != nil {
+			names = append(names, ref.Name)
+		}
+	}
+
+	for _, t := range b.Translations {
+		names = append(names, t.ConfigMapKeyRef.Name)
+	}
+
+	return names
+}
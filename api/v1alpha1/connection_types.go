@@ -46,10 +46,31 @@ type ConnectionSpec struct {
 	// +optional
 	Parameters *ConnectionParameters `json:"parameters,omitempty"`
 
+	// Parameters of the connection resolved from Secrets or external
+	// credential providers. Overrides parameters of the same name.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ParametersFrom []ParameterSource `json:"parametersFrom,omitempty"`
+
 	// Permissions.
 	//
 	// +optional
 	Permissions *ConnectionPermissions `json:"permissions,omitempty"`
+
+	// Name of the guacd pool handling the connection. The pool has to be
+	// defined in the referenced Guacamole instance. Uses the default guacd
+	// deployment if not specified.
+	//
+	// +optional
+	GuacdPool *string `json:"guacdPool,omitempty"`
+
+	// Session recording. Requires recording to be configured in the
+	// referenced Guacamole instance.
+	//
+	// +optional
+	Recording *ConnectionRecording `json:"recording,omitempty"`
 }
 
 // ConnectionStatus defines the observed state of Connection.
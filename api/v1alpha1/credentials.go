@@ -0,0 +1,90 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Credentials configures external credential providers used to
+// resolve connection parameters.
+type Credentials struct {
+	// HashiCorp Vault KV version 2 secrets engine.
+	// +optional
+	Vault *Vault `json:"vault,omitempty"`
+}
+
+// Vault configures access to a KV version 2 secrets engine. The operator
+// authenticates with its service account via the Kubernetes auth method.
+type Vault struct {
+	// Address of Vault, e.g. `https://vault.example.net:8200`.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Address string `json:"address"`
+
+	// Mount path of the KV secrets engine.
+	// +optional
+	// +kubebuilder:default=secret
+	Mount string `json:"mount,omitempty"`
+
+	// Prefix of the paths of secrets referenced by connections. Connections
+	// can only read secrets below the prefix. `$(NAMESPACE)` is replaced by
+	// the namespace of the connection to scope secrets per namespace, e.g.
+	// `guacamole/$(NAMESPACE)`. Defaults to `$(NAMESPACE)`, set a prefix
+	// without it to share secrets between namespaces.
+	// +optional
+	// +kubebuilder:default="$(NAMESPACE)"
+	// +kubebuilder:validation:XValidation:rule="!self.startsWith('/') && self.split('/').all(s, s != '..')",message="path prefix must be relative and must not contain '..'"
+	PathPrefix string `json:"pathPrefix,omitempty"`
+
+	// Kubernetes auth method.
+	Auth VaultKubernetesAuth `json:"auth"`
+
+	// CA certificate (PEM) to verify the certificate of Vault.
+	// +optional
+	CACertSecretRef *corev1.SecretKeySelector `json:"caCertSecretRef,omitempty"`
+
+	// Duration resolved secrets are cached. Connections are synchronized
+	// again once cached secrets expire to pick up rotated credentials.
+	// Leases of secrets take precedence.
+	// +optional
+	// +kubebuilder:default="5m"
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// VaultKubernetesAuth configures the Kubernetes auth method of Vault.
+type VaultKubernetesAuth struct {
+	// Mount path of the auth method.
+	// +optional
+	// +kubebuilder:default=kubernetes
+	Mount string `json:"mount,omitempty"`
+
+	// Role to authenticate with.
+	Role string `json:"role"`
+}
+
+// ParameterSource defines the source of a connection parameter.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.secretKeyRef) != has(self.vault)",message="exactly one of secretKeyRef or vault must be set"
+type ParameterSource struct {
+	// Name of the connection parameter, e.g. `password`.
+	Name string `json:"name"`
+
+	// Key of a Secret in the namespace of the connection.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+
+	// Key of a Vault secret. Requires Vault to be configured
+	// in the referenced Guacamole instance.
+	// +optional
+	Vault *VaultSecretKeySelector `json:"vault,omitempty"`
+}
+
+// VaultSecretKeySelector selects a key of a Vault secret.
+type VaultSecretKeySelector struct {
+	// Path of the secret within the KV secrets engine, relative to the
+	// path prefix of the instance.
+	// +kubebuilder:validation:XValidation:rule="!self.startsWith('/') && self.split('/').all(s, s != '..')",message="path must be relative and must not contain '..'"
+	Path string `json:"path"`
+
+	// Key within the secret.
+	Key string `json:"key"`
+}
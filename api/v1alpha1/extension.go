@@ -0,0 +1,79 @@
+package v1alpha1
+
+import corev1 "k8s.io/api/core/v1"
+
+// ExtensionSignature defines the signature of an extension.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.cosign) != has(self.gpg)",message="exactly one of cosign or gpg must be set"
+type ExtensionSignature struct {
+	// Signature created by `cosign sign-blob` with a key pair.
+	// Keyless signatures are not supported.
+	// +optional
+	Cosign *CosignSignature `json:"cosign,omitempty"`
+
+	// Detached GPG signature, either armored or binary.
+	// +optional
+	GPG *GPGSignature `json:"gpg,omitempty"`
+}
+
+// CosignSignature defines a cosign signature.
+type CosignSignature struct {
+	// URI of the signature. Defaults to the URI of the extension with
+	// suffix `.sig`, required for all other sources.
+	// +optional
+	URI string `json:"uri,omitempty"`
+
+	// Public key (PEM) of the signer.
+	PublicKeyRef corev1.ConfigMapKeySelector `json:"publicKeyRef"`
+}
+
+// GPGSignature defines a detached GPG signature.
+type GPGSignature struct {
+	// URI of the signature. Defaults to the URI of the extension with
+	// suffix `.asc`, required for all other sources.
+	// +optional
+	URI string `json:"uri,omitempty"`
+
+	// Public keys of the signers, either armored or binary.
+	PublicKeyRef corev1.ConfigMapKeySelector `json:"publicKeyRef"`
+}
+
+// Verified returns whether the extension is verified before provisioning.
+func (e *Extension) Verified() bool {
+	return e.SHA256 != "" || e.Signature != nil
+}
+
+// Authenticated returns whether the download of the extension requires
+// credentials or CA certificates.
+func (e *Extension) Authenticated() bool {
+	return e.CredentialsSecretRef != nil || e.CACertSecretRef != nil
+}
+
+// ImageExtensionSource defines an image or OCI artifact containing an extension.
+type ImageExtensionSource struct {
+	// Image or artifact reference.
+	Reference string `json:"reference"`
+
+	// Policy for pulling the image or artifact.
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
+
+	// Path of the extension within the image, either a file or
+	// a directory. Defaults to the whole image.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="!self.contains('..')",message="path must not contain '..'"
+	Path string `json:"path,omitempty"`
+}
+
+// PersistentVolumeClaimExtensionSource defines a path on a
+// PersistentVolumeClaim containing an extension.
+type PersistentVolumeClaimExtensionSource struct {
+	// Name of the claim.
+	ClaimName string `json:"claimName"`
+
+	// Path of the extension on the volume, either a file or
+	// a directory. Defaults to the whole volume.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="!self.contains('..')",message="path must not contain '..'"
+	Path string `json:"path,omitempty"`
+}
@@ -0,0 +1,205 @@
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SchemaVersionAnnotation defines the applied database schema version
+// of an existing database adopted by an instance.
+const SchemaVersionAnnotation = "guacamole-operator.github.io/schema-version"
+
+// GuacamoleConditionType is the type for a Guacamole condition.
+type GuacamoleConditionType string
+
+const (
+	// GuacamoleSchemaReady indicates whether the database schema
+	// matches the deployed Guacamole version.
+	GuacamoleSchemaReady GuacamoleConditionType = "SchemaReady"
+	// GuacamoleExtensionsVerified indicates whether the extensions
+	// with checksums or signatures passed verification.
+	GuacamoleExtensionsVerified GuacamoleConditionType = "ExtensionsVerified"
+	// GuacamoleAuthExtensionsLoaded indicates whether the extensions of
+	// all configured authentication methods are loaded.
+	GuacamoleAuthExtensionsLoaded GuacamoleConditionType = "AuthExtensionsLoaded"
+	// GuacamoleTOTPReset indicates whether the TOTP enrollments requested
+	// via annotation were reset.
+	GuacamoleTOTPReset GuacamoleConditionType = "TOTPReset"
+)
+
+// GuacamoleConditionReason is the reason type for a Guacamole condition.
+type GuacamoleConditionReason string
+
+const (
+	// GuacamoleSchemaUpToDate is the reason when the schema is up to date.
+	GuacamoleSchemaUpToDate GuacamoleConditionReason = "UpToDate"
+	// GuacamoleSchemaUpgrading is the reason when a schema upgrade is running.
+	GuacamoleSchemaUpgrading GuacamoleConditionReason = "Upgrading"
+	// GuacamoleSchemaUpgradeFailed is the reason when a schema upgrade failed.
+	GuacamoleSchemaUpgradeFailed GuacamoleConditionReason = "UpgradeFailed"
+	// GuacamoleSchemaDowngrade is the reason when the deployed version is
+	// older than the schema. Guacamole does not support downgrades.
+	GuacamoleSchemaDowngrade GuacamoleConditionReason = "DowngradeNotSupported"
+	// GuacamoleSchemaVersionUnknown is the reason when the schema version
+	// of an existing deployment cannot be determined.
+	GuacamoleSchemaVersionUnknown GuacamoleConditionReason = "VersionUnknown"
+	// GuacamoleExtensionsVerifiedReason is the reason when all extensions
+	// passed verification.
+	GuacamoleExtensionsVerifiedReason GuacamoleConditionReason = "Verified"
+	// GuacamoleExtensionsVerificationPending is the reason when extensions
+	// were not verified yet.
+	GuacamoleExtensionsVerificationPending GuacamoleConditionReason = "Pending"
+	// GuacamoleExtensionVerificationFailed is the reason when an extension
+	// failed verification.
+	GuacamoleExtensionVerificationFailed GuacamoleConditionReason = "VerificationFailed"
+	// GuacamoleAuthExtensionsLoadedReason is the reason when the extensions
+	// of all configured authentication methods are loaded.
+	GuacamoleAuthExtensionsLoadedReason GuacamoleConditionReason = "Loaded"
+	// GuacamoleAuthExtensionsNotLoaded is the reason when the extension of
+	// a configured authentication method is not loaded.
+	GuacamoleAuthExtensionsNotLoaded GuacamoleConditionReason = "NotLoaded"
+	// GuacamoleTOTPResetReason is the reason when TOTP enrollments were reset.
+	GuacamoleTOTPResetReason GuacamoleConditionReason = "Reset"
+	// GuacamoleTOTPNotConfigured is the reason when a reset is requested
+	// for an instance without TOTP.
+	GuacamoleTOTPNotConfigured GuacamoleConditionReason = "NotConfigured"
+)
+
+// MarkSchemaUpToDate sets the schema condition to true.
+// Indicates that the schema matches the deployed version.
+func (s *GuacamoleStatus) MarkSchemaUpToDate(version string) {
+	s.SchemaVersion = version
+
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:    string(GuacamoleSchemaReady),
+		Reason:  string(GuacamoleSchemaUpToDate),
+		Status:  metav1.ConditionTrue,
+		Message: "Database schema is at version " + version + ".",
+	})
+}
+
+// MarkSchemaUpgrading sets the schema condition to false.
+// Indicates that a schema upgrade is in progress.
+func (s *GuacamoleStatus) MarkSchemaUpgrading(from, to string) {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:    string(GuacamoleSchemaReady),
+		Reason:  string(GuacamoleSchemaUpgrading),
+		Status:  metav1.ConditionFalse,
+		Message: "Upgrading database schema from version " + from + " to " + to + ".",
+	})
+}
+
+// MarkSchemaUpgradeFailed sets the schema condition to false.
+// Indicates that a schema upgrade failed and the rollout is blocked.
+func (s *GuacamoleStatus) MarkSchemaUpgradeFailed(from, to, job string) {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:   string(GuacamoleSchemaReady),
+		Reason: string(GuacamoleSchemaUpgradeFailed),
+		Status: metav1.ConditionFalse,
+		Message: "Upgrading database schema from version " + from + " to " + to +
+			" failed, rollout is blocked. Inspect and delete job " + job + " to retry.",
+	})
+}
+
+// MarkSchemaDowngrade sets the schema condition to false.
+// Indicates that the deployed version is older than the schema.
+func (s *GuacamoleStatus) MarkSchemaDowngrade(from, to string) {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:   string(GuacamoleSchemaReady),
+		Reason: string(GuacamoleSchemaDowngrade),
+		Status: metav1.ConditionFalse,
+		Message: "Database schema version " + from + " is newer than " + to +
+			", rollout is blocked.",
+	})
+}
+
+// MarkSchemaVersionUnknown sets the schema condition to false.
+// Indicates that the schema version of the deployed image is unknown.
+func (s *GuacamoleStatus) MarkSchemaVersionUnknown(image string) {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:   string(GuacamoleSchemaReady),
+		Reason: string(GuacamoleSchemaVersionUnknown),
+		Status: metav1.ConditionFalse,
+		Message: "Database schema version of the deployed image " + image + " is unknown, rollout is blocked. " +
+			"Set the annotation " + SchemaVersionAnnotation + " to the schema version.",
+	})
+}
+
+// MarkExtensionsVerified sets the extensions condition to true.
+// Indicates that all extensions passed verification.
+func (s *GuacamoleStatus) MarkExtensionsVerified() {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:    string(GuacamoleExtensionsVerified),
+		Reason:  string(GuacamoleExtensionsVerifiedReason),
+		Status:  metav1.ConditionTrue,
+		Message: "Extensions passed verification.",
+	})
+}
+
+// MarkExtensionsVerificationPending sets the extensions condition to unknown.
+// Indicates that extensions were not verified yet.
+func (s *GuacamoleStatus) MarkExtensionsVerificationPending() {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:    string(GuacamoleExtensionsVerified),
+		Reason:  string(GuacamoleExtensionsVerificationPending),
+		Status:  metav1.ConditionUnknown,
+		Message: "Extensions are not verified yet.",
+	})
+}
+
+// MarkExtensionVerificationFailed sets the extensions condition to false.
+// Indicates that an extension failed verification and the rollout is blocked.
+func (s *GuacamoleStatus) MarkExtensionVerificationFailed(message string) {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:    string(GuacamoleExtensionsVerified),
+		Reason:  string(GuacamoleExtensionVerificationFailed),
+		Status:  metav1.ConditionFalse,
+		Message: message,
+	})
+}
+
+// MarkAuthExtensionsLoaded sets the authentication extensions condition to true.
+// Indicates that the extensions of all configured methods are loaded.
+func (s *GuacamoleStatus) MarkAuthExtensionsLoaded() {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:    string(GuacamoleAuthExtensionsLoaded),
+		Reason:  string(GuacamoleAuthExtensionsLoadedReason),
+		Status:  metav1.ConditionTrue,
+		Message: "Extensions of all configured authentication methods are loaded.",
+	})
+}
+
+// MarkAuthExtensionsNotLoaded sets the authentication extensions condition
+// to false. Indicates that the extension of a configured method is not
+// loaded by the Guacamole image, e.g. due to missing settings.
+func (s *GuacamoleStatus) MarkAuthExtensionsNotLoaded(message string) {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:    string(GuacamoleAuthExtensionsLoaded),
+		Reason:  string(GuacamoleAuthExtensionsNotLoaded),
+		Status:  metav1.ConditionFalse,
+		Message: message,
+	})
+}
+
+// MarkTOTPReset sets the TOTP reset condition to true.
+// Indicates that the TOTP enrollments of the users were reset.
+func (s *GuacamoleStatus) MarkTOTPReset(users string) {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:    string(GuacamoleTOTPReset),
+		Reason:  string(GuacamoleTOTPResetReason),
+		Status:  metav1.ConditionTrue,
+		Message: "TOTP enrollments of users " + users + " were reset.",
+	})
+}
+
+// MarkTOTPNotConfigured sets the TOTP reset condition to false.
+// Indicates that a reset was requested but TOTP is not configured.
+func (s *GuacamoleStatus) MarkTOTPNotConfigured() {
+	meta.SetStatusCondition(&s.Conditions, metav1.Condition{
+		Type:   string(GuacamoleTOTPReset),
+		Reason: string(GuacamoleTOTPNotConfigured),
+		Status: metav1.ConditionFalse,
+		Message: "TOTP is not configured, enrollments are not reset. " +
+			"Configure TOTP or remove the annotation " + ResetTOTPAnnotation + ".",
+	})
+}
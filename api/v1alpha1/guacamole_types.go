@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	addonv1alpha1 "sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/addon/pkg/apis/v1alpha1"
 )
@@ -28,6 +29,18 @@ type GuacamoleSpec struct {
 	addonv1alpha1.CommonSpec `json:",inline"`
 	addonv1alpha1.PatchSpec  `json:",inline"`
 
+	// Policy for upgrades to newer package versions of the channel. With
+	// `Manual`, an instance stays at its current version until the upgrade
+	// is approved by setting the `guacamole-operator.github.io/approve-upgrade`
+	// annotation to the available version or by pinning `version`.
+	// +optional
+	// +kubebuilder:default=Automatic
+	UpgradePolicy UpgradePolicy `json:"upgradePolicy,omitempty"`
+
+	// Image overrides of the components.
+	// +optional
+	Images *Images `json:"images,omitempty"`
+
 	// Authentication method configuration (required).
 	Auth Auth `json:"auth,omitempty"`
 
@@ -35,17 +48,57 @@ type GuacamoleSpec struct {
 	// +optional
 	TLS *TLS `json:"tls,omitempty"`
 
+	// External credential providers for connection parameters.
+	// +optional
+	Credentials *Credentials `json:"credentials,omitempty"`
+
 	// Additional settings.
 	// +optional
 	AdditionalSettings map[string]string `json:"additionalSettings,omitempty"`
 
+	// Files merged into `guacamole.properties` in order. Properties
+	// defined more than once are taken from the last file.
+	// +optional
+	Properties []PropertiesSource `json:"properties,omitempty"`
+
+	// Logging of the Guacamole web application.
+	// +optional
+	Logging *Logging `json:"logging,omitempty"`
+
 	// Extensions to provision.
 	// +optional
 	Extensions []Extension `json:"extensions,omitempty"`
 
+	// Branding of the web application.
+	// +optional
+	Branding *Branding `json:"branding,omitempty"`
+
+	// Session recording storage.
+	// +optional
+	Recording *Recording `json:"recording,omitempty"`
+
+	// Guacamole web application configuration.
+	// +optional
+	Guacamole *WebApp `json:"guacamole,omitempty"`
+
 	// Guacd configuration.
 	// +optional
 	Guacd *Guacd `json:"guacd,omitempty"`
+
+	// Additional guacd pools, each rendered as a separate guacd deployment
+	// and service. Connections select a pool via `guacdPool`.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	GuacdPools []GuacdPool `json:"guacdPools,omitempty"`
+
+	// NetworkPolicies restricting traffic of the web application and guacd.
+	// +optional
+	NetworkPolicy *NetworkPolicy `json:"networkPolicy,omitempty"`
+
+	// Prometheus metrics of the web application and guacd.
+	// +optional
+	Metrics *Metrics `json:"metrics,omitempty"`
 }
 
 // GuacamoleStatus defines the observed state of Guacamole.
@@ -58,10 +111,36 @@ type GuacamoleStatus struct {
 	//
 	// +optional
 	Access *Access `json:"access,omitempty"`
+
+	// Package version of the channel applied to the instance.
+	//
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Latest package version of the channel. Differs from `version`
+	// if an upgrade is available.
+	//
+	// +optional
+	AvailableVersion string `json:"availableVersion,omitempty"`
+
+	// Version of the applied database schema. Set after the database
+	// was initialized or upgraded. Existing databases can be adopted by
+	// setting the `guacamole-operator.github.io/schema-version` annotation
+	// to the Guacamole version the schema was created with.
+	//
+	// +optional
+	SchemaVersion string `json:"schemaVersion,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state.
+	//
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.version`
+//+kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.availableVersion`
 
 // Guacamole is the Schema for the guacamoles API.
 type Guacamole struct {
@@ -107,10 +186,92 @@ func init() {
 	SchemeBuilder.Register(&Guacamole{}, &GuacamoleList{})
 }
 
+// UpgradePolicy defines how upgrades to newer package versions are applied.
+// +kubebuilder:validation:Enum=Automatic;Manual
+type UpgradePolicy string
+
+const (
+	// UpgradePolicyAutomatic applies the latest version of the channel.
+	UpgradePolicyAutomatic UpgradePolicy = "Automatic"
+	// UpgradePolicyManual applies newer versions once approved.
+	UpgradePolicyManual UpgradePolicy = "Manual"
+)
+
+// ApproveUpgradeAnnotation approves the upgrade of an instance with
+// upgrade policy `Manual` to the given package version.
+const ApproveUpgradeAnnotation = "guacamole-operator.github.io/approve-upgrade"
+
+// PackageVersionLabel records the package version on the Guacamole
+// deployment of an instance.
+const PackageVersionLabel = "guacamole-operator.github.io/package-version"
+
 // Extension...
+//
+// +kubebuilder:validation:XValidation:rule="[has(self.name), has(self.uri), has(self.image), has(self.configMapKeyRef), has(self.secretKeyRef), has(self.persistentVolumeClaim)].filter(x, x).size() == 1",message="exactly one of name, uri, image, configMapKeyRef, secretKeyRef or persistentVolumeClaim must be set"
+// +kubebuilder:validation:XValidation:rule="has(self.uri) || !(has(self.credentialsSecretRef) || has(self.caCertSecretRef))",message="credentialsSecretRef and caCertSecretRef require uri"
+// +kubebuilder:validation:XValidation:rule="!has(self.priority) || has(self.namespace) || has(self.name)",message="priority requires namespace"
+// +kubebuilder:validation:XValidation:rule="!has(self.signature) || has(self.uri) || has(self.name) || (has(self.signature.cosign) && has(self.signature.cosign.uri)) || (has(self.signature.gpg) && has(self.signature.gpg.uri))",message="signature.uri is required unless the extension is downloaded from a URI"
 type Extension struct {
+	ExtensionPriority `json:",inline"`
+
+	// Name of a well-known extension in the catalog of the Guacamole
+	// version, e.g. `totp` or `history-recording-storage`. The download
+	// URI and required properties are taken from the catalog.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the extension as declared in its `guac-manifest.json`.
+	// Required to set a priority, unless taken from the catalog.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
 	// URI for the extension.
-	URI string `json:"uri"`
+	// +optional
+	URI string `json:"uri,omitempty"`
+
+	// Image or OCI artifact containing the extension, mounted as image
+	// volume. Requires a cluster with image volume support.
+	// +optional
+	Image *ImageExtensionSource `json:"image,omitempty"`
+
+	// Key of a ConfigMap containing the extension as binary data.
+	// The key is used as file name, e.g. `branding.jar`.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// Key of a Secret containing the extension. The key is used
+	// as file name, e.g. `guacamole-auth-custom.jar`.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+
+	// Path on a PersistentVolumeClaim containing the extension. Claims
+	// should support ReadOnlyMany for instances with multiple replicas.
+	// +optional
+	PersistentVolumeClaim *PersistentVolumeClaimExtensionSource `json:"persistentVolumeClaim,omitempty"`
+
+	// Secret with credentials for the download from the URI, either keys
+	// `username` and `password` for basic authentication, `token` for
+	// a bearer token or `.netrc` with a netrc file. Basic authentication
+	// and bearer tokens are only sent to the host of the URI.
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+
+	// CA certificates (PEM) to verify the server of the URI in addition
+	// to the system certificates.
+	// +optional
+	CACertSecretRef *corev1.SecretKeySelector `json:"caCertSecretRef,omitempty"`
+
+	// Expected SHA-256 digest (hex) of the downloaded file. Extensions
+	// failing verification are not provisioned.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-fA-F0-9]{64}$`
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Signature of the downloaded file. Extensions failing verification
+	// are not provisioned.
+	// +optional
+	Signature *ExtensionSignature `json:"signature,omitempty"`
 }
 
 // Access...
@@ -121,10 +282,51 @@ type Access struct {
 	Source string `json:"source"`
 }
 
+// WebApp...
+type WebApp struct {
+	// +optional
+	Metadata *ObjectMeta `json:"metadata,omitempty"`
+
+	// Overrides for the pod template.
+	// +optional
+	Template *PodTemplate `json:"template,omitempty"`
+}
+
 // Guacd...
 type Guacd struct {
 	// +optional
 	Metadata *ObjectMeta `json:"metadata,omitempty"`
+
+	// Overrides for the pod template.
+	// +optional
+	Template *PodTemplate `json:"template,omitempty"`
+
+	// TLS between Guacamole and guacd.
+	// +optional
+	TLS *GuacdTLS `json:"tls,omitempty"`
+}
+
+// GuacdPool...
+type GuacdPool struct {
+	// Name of the pool.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=32
+	Name string `json:"name"`
+
+	// Annotations of the pool are merged with the ones of the
+	// default guacd deployment.
+	// +optional
+	Metadata *ObjectMeta `json:"metadata,omitempty"`
+
+	// Overrides for the pod template, applied on top of the
+	// overrides of the default guacd deployment.
+	// +optional
+	Template *PodTemplate `json:"template,omitempty"`
+}
+
+// GuacdPoolServiceName returns the name of the service of a guacd pool.
+func GuacdPoolServiceName(instance, pool string) string {
+	return "guacd-" + pool + "-" + instance
 }
 
 // ObjectMeta...
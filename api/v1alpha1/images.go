@@ -0,0 +1,37 @@
+package v1alpha1
+
+// Images defines image overrides of the components of an instance. Images
+// are rewritten to the registry mirror of the operator if configured.
+type Images struct {
+	// Image of the Guacamole web application. Defaults to the image of
+	// the channel package. Schema upgrades rely on the tag being the
+	// Guacamole version.
+	// +optional
+	Guacamole string `json:"guacamole,omitempty"`
+
+	// Image of guacd, including guacd pools. Defaults to the image of
+	// the channel package.
+	// +optional
+	Guacd string `json:"guacd,omitempty"`
+
+	// Image of the extension downloader.
+	// +optional
+	ExtensionDownloader string `json:"extensionDownloader,omitempty"`
+
+	// Image of the recording retention job.
+	// +optional
+	RecordingRetention string `json:"recordingRetention,omitempty"`
+
+	// Image providing the JMX exporter agent of the web application.
+	// +optional
+	JMXExporter string `json:"jmxExporter,omitempty"`
+
+	// Image of the guacd metrics exporter.
+	// +optional
+	GuacdExporter string `json:"guacdExporter,omitempty"`
+
+	// Image providing the client of the configured database, used to
+	// initialize and upgrade the schema.
+	// +optional
+	Database string `json:"database,omitempty"`
+}
@@ -0,0 +1,32 @@
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// Metrics configures Prometheus metrics of an instance. JVM and Tomcat
+// metrics of the web application are exposed by the JMX exporter agent,
+// metrics of guacd by an exporter sidecar. Gauges of active connections
+// and users are exposed by the operator.
+type Metrics struct {
+	// ServiceMonitor scraping the web application and guacd. Requires
+	// the Prometheus operator.
+	// +optional
+	ServiceMonitor *ServiceMonitor `json:"serviceMonitor,omitempty"`
+
+	// Expose the active connections of every connection in addition to
+	// the ones per connection group. The number of series grows with
+	// the number of connections.
+	// +optional
+	PerConnection bool `json:"perConnection,omitempty"`
+}
+
+// ServiceMonitor configures the ServiceMonitor of an instance.
+type ServiceMonitor struct {
+	// Additional labels of the ServiceMonitor, e.g. to be selected by
+	// a Prometheus instance.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Scrape interval. Defaults to the interval of Prometheus.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
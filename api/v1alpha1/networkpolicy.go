@@ -0,0 +1,29 @@
+package v1alpha1
+
+import networkingv1 "k8s.io/api/networking/v1"
+
+// NetworkPolicy configures the NetworkPolicies rendered for an instance.
+// Ingress to guacd is restricted to the web application. Ingress to the
+// web application is restricted to the operator and the given namespaces.
+// Egress is restricted only if configured, DNS is always allowed.
+type NetworkPolicy struct {
+	// Namespaces allowed to connect to the web application, e.g. the
+	// namespace of the ingress or gateway controller exposing it.
+	// +optional
+	IngressNamespaces []string `json:"ingressNamespaces,omitempty"`
+
+	// Egress rules of the web application, e.g. to the database, the
+	// identity provider and the sources of extensions. Connections to
+	// guacd are always allowed.
+	// +optional
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+
+	// Namespaces allowed to scrape metrics, e.g. the namespace of
+	// Prometheus. Only used if metrics are enabled.
+	// +optional
+	MetricsNamespaces []string `json:"metricsNamespaces,omitempty"`
+
+	// CIDRs of the target hosts guacd connects to.
+	// +optional
+	TargetCIDRs []string `json:"targetCIDRs,omitempty"`
+}
@@ -0,0 +1,57 @@
+package v1alpha1
+
+import corev1 "k8s.io/api/core/v1"
+
+// PodTemplate defines overrides for the pod of a managed component.
+type PodTemplate struct {
+	// Node selector for the pod.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the pod.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity of the pod.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Priority class of the pod.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Image pull secrets for all containers of the pod.
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Security context of the pod.
+	// +optional
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+
+	// Overrides for containers and init containers of the pod,
+	// matched by container name.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Containers []ContainerTemplate `json:"containers,omitempty"`
+}
+
+// ContainerTemplate defines overrides for a container of a managed component.
+type ContainerTemplate struct {
+	// Name of the container, e.g. `guacamole`, `guacd`, `extension-dl`,
+	// `create-init-db` or `load-db`.
+	Name string `json:"name"`
+
+	// Compute resources of the container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Security context of the container.
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// Additional environment variables. Variables with the same name
+	// as an operator managed variable replace it.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+}
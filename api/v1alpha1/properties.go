@@ -0,0 +1,28 @@
+package v1alpha1
+
+import corev1 "k8s.io/api/core/v1"
+
+// PropertiesSource references a file in `guacamole.properties` format.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.configMapKeyRef) != has(self.secretKeyRef)",message="exactly one of configMapKeyRef or secretKeyRef must be set"
+type PropertiesSource struct {
+	// Key of a ConfigMap.
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// Key of a Secret, e.g. for properties containing credentials.
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// Logging configures the logging of the Guacamole web application.
+type Logging struct {
+	// Log level.
+	// +optional
+	// +kubebuilder:validation:Enum=error;warn;info;debug;trace
+	Level string `json:"level,omitempty"`
+
+	// Custom `logback.xml`. Takes precedence over the log level.
+	// +optional
+	LogbackRef *corev1.ConfigMapKeySelector `json:"logbackRef,omitempty"`
+}
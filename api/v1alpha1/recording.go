@@ -0,0 +1,124 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Recording configures session recording storage for Guacamole.
+// Recordings are written by guacd and played back via the
+// history recording storage extension.
+type Recording struct {
+	// Existing claim to store recordings in. If not set, a claim is
+	// provisioned and deleted together with the recording configuration.
+	// +optional
+	ExistingClaim string `json:"existingClaim,omitempty"`
+
+	// Size of the provisioned claim.
+	// +optional
+	// +kubebuilder:default="10Gi"
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// Storage class of the provisioned claim.
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Access modes of the provisioned claim. The claim is shared by guacd
+	// and Guacamole and therefore defaults to ReadWriteMany.
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
+	// Retention policy for recordings.
+	// +optional
+	Retention *RecordingRetention `json:"retention,omitempty"`
+}
+
+// RecordingRetention defines when recordings are removed. Retention is
+// enforced periodically by a CronJob. Recordings are archived before
+// removal if an archive is configured.
+// +kubebuilder:validation:XValidation:rule="has(self.maxAge) || has(self.maxSize) || has(self.overrides)",message="one of maxAge, maxSize or overrides is required"
+type RecordingRetention struct {
+	// Maximum age of recordings.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+
+	// Maximum total size of recordings. The oldest recordings are
+	// removed first once the size is exceeded.
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+
+	// Maximum age of recordings per connection. Overrides
+	// the maximum age for recordings of the connection.
+	// +optional
+	// +listType=map
+	// +listMapKey=connection
+	Overrides []RecordingRetentionOverride `json:"overrides,omitempty"`
+
+	// Schedule in cron format.
+	// +optional
+	// +kubebuilder:default="0 * * * *"
+	Schedule string `json:"schedule,omitempty"`
+
+	// Archive for expired recordings.
+	// +optional
+	Archive *RecordingArchive `json:"archive,omitempty"`
+}
+
+// RecordingRetentionOverride defines the retention of the recordings
+// of a single connection.
+type RecordingRetentionOverride struct {
+	// Recording name of the connection, which defaults to the
+	// name of the Connection resource.
+	Connection string `json:"connection"`
+
+	// Maximum age of recordings of the connection.
+	MaxAge metav1.Duration `json:"maxAge"`
+}
+
+// RecordingArchive defines where expired recordings are archived.
+type RecordingArchive struct {
+	// S3 compatible bucket.
+	S3 *S3Archive `json:"s3"`
+}
+
+// S3Archive defines an S3 compatible bucket.
+type S3Archive struct {
+	// Endpoint of the S3 API, e.g. `https://s3.eu-central-1.amazonaws.com`.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Endpoint string `json:"endpoint"`
+
+	// Name of the bucket.
+	Bucket string `json:"bucket"`
+
+	// Region of the bucket.
+	// +optional
+	// +kubebuilder:default=us-east-1
+	Region string `json:"region,omitempty"`
+
+	// Prefix of archived objects.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Secret with the keys `access-key-id` and `secret-access-key`.
+	CredentialsSecretRef corev1.LocalObjectReference `json:"credentialsSecretRef"`
+}
+
+// ConnectionRecording configures the recording of a connection.
+type ConnectionRecording struct {
+	// Name of the recording file. Defaults to the name of the connection.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Include key events in the recording.
+	// +optional
+	IncludeKeys bool `json:"includeKeys,omitempty"`
+
+	// Exclude graphical output from the recording.
+	// +optional
+	ExcludeOutput bool `json:"excludeOutput,omitempty"`
+
+	// Exclude mouse events from the recording.
+	// +optional
+	ExcludeMouse bool `json:"excludeMouse,omitempty"`
+}
@@ -8,6 +8,51 @@ type TLS struct {
 	CaCertificates *CaCertificates `json:"caCertificates,omitempty"`
 }
 
+// CaCertificates trusted by Guacamole, e.g. for OIDC or LDAP servers.
+// Each key of the secret ending with `.pem` is imported as a certificate.
 type CaCertificates struct {
 	SecretRef corev1.LocalObjectReference `json:"secretRef,omitempty"`
 }
+
+// GuacdTLS configures TLS between Guacamole and guacd.
+// The CA certificate (`ca.crt`) of the guacd certificate is added to the
+// trusted CAs of Guacamole, which replaces the default trust store
+// like `caCertificates` does.
+//
+// +kubebuilder:validation:XValidation:rule="has(self.issuerRef) != has(self.secretRef)",message="exactly one of issuerRef or secretRef must be set"
+type GuacdTLS struct {
+	// cert-manager issuer used to issue the guacd certificate.
+	// +optional
+	IssuerRef *IssuerRef `json:"issuerRef,omitempty"`
+
+	// Secret containing the guacd certificate (`tls.crt`),
+	// its key (`tls.key`) and the CA certificate (`ca.crt`).
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// SecretName returns the name of the secret holding the guacd certificate.
+func (t *GuacdTLS) SecretName(instance string) string {
+	if t.SecretRef != nil {
+		return t.SecretRef.Name
+	}
+
+	return "guacd-tls-" + instance
+}
+
+// IssuerRef references a cert-manager issuer.
+type IssuerRef struct {
+	// Name of the issuer.
+	Name string `json:"name"`
+
+	// Kind of the issuer.
+	// +optional
+	// +kubebuilder:default=Issuer
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+
+	// Group of the issuer.
+	// +optional
+	// +kubebuilder:default=cert-manager.io
+	Group string `json:"group,omitempty"`
+}
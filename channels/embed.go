@@ -0,0 +1,10 @@
+// Package channels contains the channels and packages bundled into the
+// operator binary.
+package channels
+
+import "embed"
+
+// FS contains the channel files and the `packages` directory.
+//
+//go:embed dev stable packages
+var FS embed.FS
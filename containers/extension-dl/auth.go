@@ -0,0 +1,193 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bgentry/go-netrc/netrc"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-getter/v2"
+)
+
+const (
+	// Files in the credentials directory of an extension.
+	usernameFile = "username"
+	passwordFile = "password"
+	tokenFile    = "token"
+	netrcFile    = ".netrc"
+
+	httpHeadFirstTimeout = 10 * time.Second
+	httpReadTimeout      = 30 * time.Second
+)
+
+// newClient returns the go-getter client for an extension. Credentials
+// and CA certificates only apply to HTTP(S) downloads.
+func newClient(extension Extension) (*getter.Client, error) {
+	if extension.Credentials == "" && extension.CACert == "" {
+		return getter.DefaultClient, nil
+	}
+
+	transport := cleanhttp.DefaultPooledTransport()
+
+	if extension.CACert != "" {
+		pool, err := certPool(extension.CACert)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	var rt http.RoundTripper = transport
+
+	if extension.Credentials != "" {
+		auth, err := newAuthTransport(transport, extension.Credentials, extension.URI)
+		if err != nil {
+			return nil, err
+		}
+
+		rt = auth
+	}
+
+	httpGetter := &getter.HttpGetter{
+		Client:                &http.Client{Transport: rt},
+		XTerraformGetDisabled: true,
+		HeadFirstTimeout:      httpHeadFirstTimeout,
+		ReadTimeout:           httpReadTimeout,
+	}
+
+	getters := make([]getter.Getter, 0, len(getter.Getters))
+	for _, g := range getter.Getters {
+		if _, ok := g.(*getter.HttpGetter); ok {
+			g = httpGetter
+		}
+
+		getters = append(getters, g)
+	}
+
+	return &getter.Client{
+		Getters:       getters,
+		Decompressors: getter.Decompressors,
+	}, nil
+}
+
+// certPool returns the system certificate pool extended by the
+// certificates (PEM) of a file.
+func certPool(file string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no CA certificates found in %s", file)
+	}
+
+	return pool, nil
+}
+
+// authTransport adds credentials to requests. Basic authentication and
+// bearer tokens are only sent to the host of the extension, so that they
+// are not leaked to redirect targets. Credentials of a netrc file are
+// looked up by host.
+type authTransport struct {
+	base http.RoundTripper
+
+	host     string
+	username string
+	password string
+	token    string
+	netrc    *netrc.Netrc
+}
+
+// newAuthTransport reads the credentials of a directory.
+func newAuthTransport(base http.RoundTripper, dir, src string) (*authTransport, error) {
+	t := &authTransport{base: base, host: host(src)}
+
+	read := func(name string) (string, error) {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+
+		return strings.TrimSpace(string(data)), err
+	}
+
+	var err error
+
+	if t.token, err = read(tokenFile); err != nil {
+		return nil, err
+	}
+
+	if t.username, err = read(usernameFile); err != nil {
+		return nil, err
+	}
+
+	if t.password, err = read(passwordFile); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, netrcFile)); err == nil {
+		if t.netrc, err = netrc.ParseFile(filepath.Join(dir, netrcFile)); err != nil {
+			return nil, fmt.Errorf("error parsing netrc file: %w", err)
+		}
+	}
+
+	if t.token == "" && t.username == "" && t.netrc == nil {
+		return nil, fmt.Errorf("no credentials found in %s", dir)
+	}
+
+	return t, nil
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Credentials embedded in the URI take precedence.
+	if req.Header.Get("Authorization") != "" || req.URL.User != nil {
+		return t.base.RoundTrip(req)
+	}
+
+	hostname := req.URL.Hostname()
+
+	switch {
+	case t.token != "" && hostname == t.host:
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	case t.username != "" && hostname == t.host:
+		req = req.Clone(req.Context())
+		req.SetBasicAuth(t.username, t.password)
+	case t.netrc != nil:
+		if machine := t.netrc.FindMachine(hostname); machine != nil && machine.Login != "" {
+			req = req.Clone(req.Context())
+			req.SetBasicAuth(machine.Login, machine.Password)
+		}
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// host returns the host name of a source in go-getter format.
+func host(src string) string {
+	if _, after, ok := strings.Cut(src, "::"); ok {
+		src = after
+	}
+
+	u, err := url.Parse(src)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
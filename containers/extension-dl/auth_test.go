@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFetchAuthenticated(t *testing.T) {
+	data := []byte("extension")
+
+	tests := map[string]struct {
+		files map[string]string
+		auth  func(r *http.Request) bool
+	}{
+		"basic": {
+			files: map[string]string{usernameFile: "user", passwordFile: "secret\n"},
+			auth: func(r *http.Request) bool {
+				username, password, ok := r.BasicAuth()
+				return ok && username == "user" && password == "secret"
+			},
+		},
+		"token": {
+			files: map[string]string{tokenFile: "token"},
+			auth: func(r *http.Request) bool {
+				return r.Header.Get("Authorization") == "Bearer token"
+			},
+		},
+		"netrc": {
+			files: map[string]string{netrcFile: "machine 127.0.0.1 login user password secret\n"},
+			auth: func(r *http.Request) bool {
+				username, password, ok := r.BasicAuth()
+				return ok && username == "user" && password == "secret"
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !tt.auth(r) {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				_, _ = w.Write(data)
+			}))
+			defer server.Close()
+
+			dir := t.TempDir()
+
+			credentials := filepath.Join(dir, "credentials")
+			if err := os.Mkdir(credentials, 0o700); err != nil {
+				t.Fatal(err)
+			}
+
+			for file, content := range tt.files {
+				writeFile(t, credentials, file, []byte(content))
+			}
+
+			caCert := writeFile(t, dir, "ca.crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+			uri := server.URL + "/extension.jar"
+
+			if err := fetch(context.Background(), Extension{URI: uri, CACert: caCert}, t.TempDir()); err == nil {
+				t.Error("expected unauthenticated download to fail")
+			}
+
+			dst := t.TempDir()
+
+			err := fetch(context.Background(), Extension{URI: uri, Credentials: credentials, CACert: caCert}, dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := os.Stat(filepath.Join(dst, "extension.jar")); err != nil {
+				t.Errorf("expected extension in destination: %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthTransportHost(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, tokenFile, []byte("token"))
+
+	var got string
+
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	transport, err := newAuthTransport(http.DefaultTransport, dir, "https://artifacts.example.com/extension.jar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got != "" {
+		t.Errorf("expected no credentials for other hosts, got %q", got)
+	}
+}
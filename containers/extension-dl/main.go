@@ -2,35 +2,254 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/go-getter/v2"
 )
 
-const defaultDownloadDir string = "/extensions"
+const (
+	defaultDownloadDir string = "/extensions"
+
+	// Environment variable with extensions to download and verify.
+	extensionsEnv = "EXTENSIONS"
+
+	// Exit code if the verification of an extension failed.
+	verificationFailedExitCode = 3
+
+	terminationLogPath = "/dev/termination-log"
+
+	// Prefix of the termination message if extensions were verified.
+	// Checked by the operator before reporting extensions as verified.
+	verifiedMessagePrefix = "Verified extensions:"
+)
+
+// Extension defines an extension to download.
+type Extension struct {
+	// Source of the extension in go-getter format or a local path.
+	URI string `json:"uri"`
+	// Expected SHA-256 digest (hex).
+	SHA256 string `json:"sha256,omitempty"`
+	// Cosign signature.
+	Cosign *Signature `json:"cosign,omitempty"`
+	// GPG signature.
+	GPG *Signature `json:"gpg,omitempty"`
+	// Path of a directory with credentials for the download, either
+	// files `username` and `password`, `token` or `.netrc`.
+	Credentials string `json:"credentials,omitempty"`
+	// Path of additional CA certificates (PEM) for the download.
+	CACert string `json:"caCert,omitempty"`
+}
+
+// Signature defines the signature of an extension.
+type Signature struct {
+	// Source of the signature in go-getter format.
+	URI string `json:"uri"`
+	// Path of the public key or keyring.
+	Key string `json:"key"`
+}
+
+// verified returns whether the extension is verified before placing it.
+func (e Extension) verified() bool {
+	return e.SHA256 != "" || e.Cosign != nil || e.GPG != nil
+}
+
+// verificationError indicates that an extension failed verification.
+type verificationError struct {
+	uri string
+	err error
+}
+
+func (e *verificationError) Error() string {
+	return fmt.Sprintf("verification of extension %s failed: %v", e.uri, e.err)
+}
+
+func (e *verificationError) Unwrap() error {
+	return e.err
+}
 
 func main() {
 	var dst string
 	flag.StringVar(&dst, "dst", defaultDownloadDir, "Target download directory.")
 	flag.Parse()
 
-	extensions := flag.Args()
+	extensions, err := extensionsFromEnv()
+	if err != nil {
+		fail(err)
+	}
+
+	for _, src := range flag.Args() {
+		extensions = append(extensions, Extension{URI: src})
+	}
+
 	if len(extensions) < 1 {
 		log.Print("No extensions to download, nothing to do!")
 		os.Exit(0)
 	}
 
-	for _, src := range extensions {
-		if err := download(context.Background(), src, dst); err != nil {
-			log.Fatal(err)
+	var verified []string
+
+	for _, extension := range extensions {
+		if err := fetch(context.Background(), extension, dst); err != nil {
+			fail(err)
+		}
+
+		if extension.verified() {
+			verified = append(verified, extension.URI)
+		}
+	}
+
+	if len(verified) > 0 {
+		// Reported in the container status.
+		message := verifiedMessagePrefix + " " + strings.Join(verified, ", ")
+		_ = os.WriteFile(terminationLogPath, []byte(message), 0o644) //nolint:gosec
+	}
+}
+
+// extensionsFromEnv returns the extensions defined in the environment.
+func extensionsFromEnv() ([]Extension, error) {
+	value := os.Getenv(extensionsEnv)
+	if value == "" {
+		return nil, nil
+	}
+
+	var extensions []Extension
+	if err := json.Unmarshal([]byte(value), &extensions); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", extensionsEnv, err)
+	}
+
+	return extensions, nil
+}
+
+// fail logs the error, writes it to the termination log and exits.
+func fail(err error) {
+	log.Print(err)
+
+	// Reported in the container status.
+	_ = os.WriteFile(terminationLogPath, []byte(err.Error()), 0o644) //nolint:gosec
+
+	var verr *verificationError
+	if errors.As(err, &verr) {
+		os.Exit(verificationFailedExitCode)
+	}
+
+	os.Exit(1)
+}
+
+// fetch downloads an extension. Extensions to verify are downloaded to
+// a temporary directory first and only placed into dst once verified.
+func fetch(ctx context.Context, extension Extension, dst string) error {
+	client, err := newClient(extension)
+	if err != nil {
+		return err
+	}
+
+	if !extension.verified() {
+		return download(ctx, client, extension.URI, dst)
+	}
+
+	if fi, err := os.Stat(extension.URI); err == nil && fi.IsDir() {
+		return &verificationError{uri: extension.URI, err: errors.New("verification of directories is not supported")}
+	}
+
+	tmp, err := os.MkdirTemp("", "extension-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	// Archives are verified as a whole, a subdirectory is only
+	// extracted once verified.
+	src, subDir := getter.SourceDirSubdir(extension.URI)
+
+	// Keep the file name, archives are detected by their extension.
+	file := filepath.Join(tmp, fileName(src))
+	if err := downloadFile(ctx, client, src, file); err != nil {
+		return err
+	}
+
+	if err := verify(ctx, client, extension, file, tmp); err != nil {
+		return &verificationError{uri: extension.URI, err: err}
+	}
+
+	log.Printf("Verified extension %s.", extension.URI)
+
+	if subDir != "" {
+		file += "//" + subDir
+	}
+
+	return download(ctx, client, file, dst)
+}
+
+// verify verifies a downloaded extension.
+func verify(ctx context.Context, client *getter.Client, extension Extension, file, tmp string) error {
+	if extension.SHA256 != "" {
+		if err := verifySHA256(file, extension.SHA256); err != nil {
+			return err
+		}
+	}
+
+	if extension.Cosign != nil {
+		signature := filepath.Join(tmp, "cosign.sig")
+		if err := downloadFile(ctx, client, extension.Cosign.URI, signature); err != nil {
+			return fmt.Errorf("error downloading cosign signature: %w", err)
+		}
+
+		if err := verifyCosign(file, signature, extension.Cosign.Key); err != nil {
+			return err
+		}
+	}
+
+	if extension.GPG != nil {
+		signature := filepath.Join(tmp, "gpg.sig")
+		if err := downloadFile(ctx, client, extension.GPG.URI, signature); err != nil {
+			return fmt.Errorf("error downloading GPG signature: %w", err)
+		}
+
+		if err := verifyGPG(file, signature, extension.GPG.Key); err != nil {
+			return err
 		}
 	}
 
+	return nil
 }
 
-func download(ctx context.Context, src, dst string) error {
+func download(ctx context.Context, client *getter.Client, src, dst string) error {
+	// Local directories, e.g. mounted image volumes, are copied as is.
+	if fi, err := os.Stat(src); err == nil && fi.IsDir() {
+		return copyDir(src, dst)
+	}
+
+	// go-getter replaces the destination with the subdirectory of
+	// a source, so it is extracted separately and copied into dst.
+	if _, subDir := getter.SourceDirSubdir(src); subDir != "" {
+		tmp, err := os.MkdirTemp("", "extension-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+
+		extracted := filepath.Join(tmp, "extension")
+		if err := get(ctx, client, src, extracted); err != nil {
+			return err
+		}
+
+		return copyDir(extracted, dst)
+	}
+
+	return get(ctx, client, src, dst)
+}
+
+// get downloads a source in go-getter format into dst.
+func get(ctx context.Context, client *getter.Client, src, dst string) error {
 	pwd, err := os.Getwd()
 	if err != nil {
 		return err
@@ -41,12 +260,57 @@ func download(ctx context.Context, src, dst string) error {
 		Dst:     dst,
 		Pwd:     pwd,
 		GetMode: getter.ModeAny,
+		Copy:    true,
 	}
 
-	_, err = getter.DefaultClient.Get(ctx, req)
+	_, err = client.Get(ctx, req)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// downloadFile downloads a single file as is, without decompression.
+func downloadFile(ctx context.Context, client *getter.Client, src, dst string) error {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	sep := "?"
+	if strings.Contains(src, "?") {
+		sep = "&"
+	}
+
+	req := &getter.Request{
+		Src:     src + sep + "archive=false",
+		Dst:     dst,
+		Pwd:     pwd,
+		GetMode: getter.ModeFile,
+	}
+
+	_, err = client.Get(ctx, req)
+
+	return err
+}
+
+// fileName returns the file name of a source in go-getter format.
+func fileName(src string) string {
+	// Strip forced getter, e.g. `s3::`.
+	if _, after, ok := strings.Cut(src, "::"); ok {
+		src = after
+	}
+
+	p := src
+	if u, err := url.Parse(src); err == nil {
+		p = u.Path
+	}
+
+	name := path.Base(p)
+	if name == "." || name == "/" || name == "" {
+		return "extension"
+	}
+
+	return name
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// verifySHA256 verifies the SHA-256 digest of a file.
+func verifySHA256(file, expected string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, expected) {
+		return fmt.Errorf("sha256 mismatch: expected %s, got %s", expected, got)
+	}
+
+	return nil
+}
+
+// verifyCosign verifies a signature created by `cosign sign-blob` with
+// a key pair. The signature is the base64 encoded signature over the
+// SHA-256 digest of the file.
+func verifyCosign(file, signatureFile, keyFile string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := os.ReadFile(signatureFile)
+	if err != nil {
+		return err
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("error decoding cosign signature: %w", err)
+	}
+
+	keyData, err := os.ReadFile(keyFile)
+	if err != nil {
+		return err
+	}
+
+	block, _ := pem.Decode(keyData)
+	if block == nil {
+		return errors.New("invalid cosign public key: no PEM block found")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid cosign public key: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest[:], signature) {
+			return errors.New("invalid cosign signature")
+		}
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("invalid cosign signature: %w", err)
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, data, signature) {
+			return errors.New("invalid cosign signature")
+		}
+	default:
+		return fmt.Errorf("unsupported cosign public key type %T", key)
+	}
+
+	return nil
+}
+
+// verifyGPG verifies a detached GPG signature, either armored or binary,
+// against a keyring, either armored or binary.
+func verifyGPG(file, signatureFile, keyringFile string) error {
+	keyringData, err := os.ReadFile(keyringFile)
+	if err != nil {
+		return err
+	}
+
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(keyringData))
+	if err != nil {
+		keyring, err = openpgp.ReadKeyRing(bytes.NewReader(keyringData))
+	}
+	if err != nil {
+		return fmt.Errorf("invalid GPG keyring: %w", err)
+	}
+
+	signature, err := os.ReadFile(signatureFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer data.Close()
+
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, data, bytes.NewReader(signature), nil)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, data, bytes.NewReader(signature), nil)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid GPG signature: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+func TestVerifySHA256(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("extension")
+	file := writeFile(t, dir, "extension.jar", data)
+
+	sum := sha256.Sum256(data)
+	if err := verifySHA256(file, hex.EncodeToString(sum[:])); err != nil {
+		t.Errorf("expected valid digest, got %v", err)
+	}
+
+	if err := verifySHA256(file, hex.EncodeToString(make([]byte, sha256.Size))); err == nil {
+		t.Error("expected digest mismatch")
+	}
+}
+
+func TestVerifyCosign(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("extension")
+	file := writeFile(t, dir, "extension.jar", data)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyFile := writeFile(t, dir, "cosign.pub", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	digest := sha256.Sum256(data)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signatureFile := writeFile(t, dir, "extension.jar.sig", []byte(base64.StdEncoding.EncodeToString(signature)))
+
+	if err := verifyCosign(file, signatureFile, keyFile); err != nil {
+		t.Errorf("expected valid signature, got %v", err)
+	}
+
+	tampered := writeFile(t, dir, "tampered.jar", []byte("tampered"))
+	if err := verifyCosign(tampered, signatureFile, keyFile); err == nil {
+		t.Error("expected invalid signature")
+	}
+}
+
+func TestVerifyGPG(t *testing.T) {
+	dir := t.TempDir()
+	data := []byte("extension")
+	file := writeFile(t, dir, "extension.jar", data)
+
+	entity, err := openpgp.NewEntity("Extension", "", "extension@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keyring bytes.Buffer
+	if err := entity.Serialize(&keyring); err != nil {
+		t.Fatal(err)
+	}
+
+	keyringFile := writeFile(t, dir, "keyring.gpg", keyring.Bytes())
+
+	var armored, binary bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&armored, entity, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := openpgp.DetachSign(&binary, entity, bytes.NewReader(data), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, signature := range map[string][]byte{"armored": armored.Bytes(), "binary": binary.Bytes()} {
+		signatureFile := writeFile(t, dir, name+".sig", signature)
+
+		if err := verifyGPG(file, signatureFile, keyringFile); err != nil {
+			t.Errorf("expected valid %s signature, got %v", name, err)
+		}
+
+		tampered := writeFile(t, dir, "tampered.jar", []byte("tampered"))
+		if err := verifyGPG(tampered, signatureFile, keyringFile); err == nil {
+			t.Errorf("expected invalid %s signature", name)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+	data := []byte("extension")
+	file := writeFile(t, src, "extension.jar", data)
+
+	sum := sha256.Sum256(data)
+
+	err := fetch(context.Background(), Extension{URI: file, SHA256: hex.EncodeToString(sum[:])}, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dst, "extension.jar")); err != nil {
+		t.Errorf("expected verified extension in destination: %v", err)
+	}
+
+	// Extensions failing verification are not placed.
+	other := t.TempDir()
+
+	err = fetch(context.Background(), Extension{URI: file, SHA256: hex.EncodeToString(make([]byte, sha256.Size))}, other)
+
+	var verr *verificationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected verification error, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(other, "extension.jar")); !os.IsNotExist(err) {
+		t.Error("expected no extension in destination")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/guacamole-auth-quickconnect-1.6.0.jar":       "guacamole-auth-quickconnect-1.6.0.jar",
+		"https://example.com/guacamole-auth-sso-1.6.0.tar.gz?archive=zip": "guacamole-auth-sso-1.6.0.tar.gz",
+		"s3::https://s3.amazonaws.com/bucket/extensions/branding.jar":     "branding.jar",
+		"https://example.com/": "extension",
+	}
+
+	for src, want := range tests {
+		if got := fileName(src); got != want {
+			t.Errorf("fileName(%q) = %q, want %q", src, got, want)
+		}
+	}
+}
+
+func TestFetchDirectory(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(src, "lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, src, "extension.jar", []byte("extension"))
+	writeFile(t, filepath.Join(src, "lib"), "driver.jar", []byte("driver"))
+
+	if err := fetch(context.Background(), Extension{URI: src}, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"extension.jar", "lib/driver.jar"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err != nil {
+			t.Errorf("expected %s in destination: %v", name, err)
+		}
+	}
+}
+
+func TestFetchSubdirectory(t *testing.T) {
+	src := t.TempDir()
+	dst := t.TempDir()
+
+	writeFile(t, dst, "existing.jar", []byte("existing"))
+
+	var archive bytes.Buffer
+
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+
+	for name, data := range map[string][]byte{
+		"guacamole-auth-totp-1.6.0/guacamole-auth-totp-1.6.0.jar": []byte("extension"),
+		"guacamole-auth-totp-1.6.0/LICENSE":                       []byte("license"),
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file := writeFile(t, src, "guacamole-auth-totp-1.6.0.tar.gz", archive.Bytes())
+	sum := sha256.Sum256(archive.Bytes())
+
+	extension := Extension{
+		URI:    file + "//guacamole-auth-totp-1.6.0",
+		SHA256: hex.EncodeToString(sum[:]),
+	}
+
+	if err := fetch(context.Background(), extension, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"existing.jar", "guacamole-auth-totp-1.6.0.jar"} {
+		if _, err := os.Stat(filepath.Join(dst, name)); err != nil {
+			t.Errorf("expected %s in destination: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultListenAddress = ":9405"
+	defaultGuacdPort     = 4822
+	readHeaderTimeout    = 10 * time.Second
+)
+
+func main() {
+	var listenAddress string
+	var guacdPort int
+	var procDir string
+
+	flag.StringVar(&listenAddress, "listen-address", defaultListenAddress, "Address to expose metrics on.")
+	flag.IntVar(&guacdPort, "guacd-port", defaultGuacdPort, "Port guacd listens on.")
+	flag.StringVar(&procDir, "proc", "/proc", "Mount point of procfs.")
+	flag.Parse()
+
+	_, p, err := net.SplitHostPort(listenAddress)
+	if err != nil {
+		log.Fatalf("invalid listen address %q: %v", listenAddress, err)
+	}
+
+	ignorePort, err := strconv.Atoi(p)
+	if err != nil {
+		log.Fatalf("invalid listen address %q: %v", listenAddress, err)
+	}
+
+	http.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		sockets, err := readSockets(procDir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WriteMetrics(w, Collect(sockets, guacdPort, ignorePort))
+	})
+
+	server := &http.Server{
+		Addr:              listenAddress,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	log.Printf("Exposing metrics of guacd on port %d at %s.", guacdPort, listenAddress)
+	log.Fatal(server.ListenAndServe())
+}
+
+// readSockets reads the IPv4 and IPv6 TCP sockets of the network
+// namespace, which is shared with guacd.
+func readSockets(procDir string) ([]Socket, error) {
+	var sockets []Socket
+
+	for _, name := range []string{"tcp", "tcp6"} {
+		f, err := os.Open(filepath.Join(procDir, "net", name))
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		s, err := ParseSockets(f)
+		f.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s sockets: %w", name, err)
+		}
+
+		sockets = append(sockets, s...)
+	}
+
+	return sockets, nil
+}
+
+// WriteMetrics writes the stats in the Prometheus text format.
+func WriteMetrics(w io.Writer, stats Stats) {
+	up := 0
+	if stats.Up {
+		up = 1
+	}
+
+	fmt.Fprintln(w, "# HELP guacd_up Whether guacd is listening for connections.")
+	fmt.Fprintln(w, "# TYPE guacd_up gauge")
+	fmt.Fprintf(w, "guacd_up %d\n", up)
+
+	fmt.Fprintln(w, "# HELP guacd_connections Established connections of clients to guacd.")
+	fmt.Fprintln(w, "# TYPE guacd_connections gauge")
+	fmt.Fprintf(w, "guacd_connections %d\n", stats.Connections)
+
+	fmt.Fprintln(w, "# HELP guacd_target_connections Established connections of guacd to target hosts by port.")
+	fmt.Fprintln(w, "# TYPE guacd_target_connections gauge")
+
+	ports := make([]int, 0, len(stats.TargetConnections))
+	for p := range stats.TargetConnections {
+		ports = append(ports, p)
+	}
+
+	slices.Sort(ports)
+
+	for _, p := range ports {
+		fmt.Fprintf(w, "guacd_target_connections{port=\"%d\"} %d\n", p, stats.TargetConnections[p])
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// States of sockets in `/proc/net/tcp`.
+const (
+	stateEstablished = "01"
+	stateListen      = "0A"
+)
+
+// Socket is a TCP socket of the network namespace.
+type Socket struct {
+	LocalPort  int
+	RemotePort int
+	State      string
+}
+
+// Stats of the sockets of guacd.
+type Stats struct {
+	// Whether guacd is listening.
+	Up bool
+	// Established connections of clients, i.e. the web application.
+	Connections int
+	// Established connections to target hosts by remote port.
+	TargetConnections map[int]int
+}
+
+// ParseSockets parses the sockets of `/proc/net/tcp` or `/proc/net/tcp6`.
+func ParseSockets(r io.Reader) ([]Socket, error) {
+	var sockets []Socket
+
+	scanner := bufio.NewScanner(r)
+
+	// Skip header.
+	scanner.Scan()
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 { //nolint:mnd // sl, local_address, rem_address, st
+			continue
+		}
+
+		localPort, err := port(fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		remotePort, err := port(fields[2])
+		if err != nil {
+			return nil, err
+		}
+
+		sockets = append(sockets, Socket{
+			LocalPort:  localPort,
+			RemotePort: remotePort,
+			State:      fields[3],
+		})
+	}
+
+	return sockets, scanner.Err()
+}
+
+// port returns the port of an address like `0100007F:12D6`.
+func port(address string) (int, error) {
+	_, p, found := strings.Cut(address, ":")
+	if !found {
+		return 0, fmt.Errorf("invalid address %q", address)
+	}
+
+	v, err := strconv.ParseUint(p, 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid port of address %q: %w", address, err)
+	}
+
+	return int(v), nil
+}
+
+// Collect derives the stats of guacd listening on guacdPort. Sockets of
+// the exporter listening on ignorePort are not counted.
+func Collect(sockets []Socket, guacdPort, ignorePort int) Stats {
+	stats := Stats{TargetConnections: map[int]int{}}
+
+	for _, s := range sockets {
+		switch {
+		case s.State == stateListen && s.LocalPort == guacdPort:
+			stats.Up = true
+		case s.State != stateEstablished || s.LocalPort == ignorePort || s.RemotePort == ignorePort:
+			continue
+		case s.LocalPort == guacdPort:
+			stats.Connections++
+		case s.RemotePort == guacdPort:
+			// Client side of connections within the pod, e.g. probes.
+			continue
+		default:
+			stats.TargetConnections[s.RemotePort]++
+		}
+	}
+
+	return stats
+}
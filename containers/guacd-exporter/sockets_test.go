@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const procNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:12D6 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 00000000:24BD 00000000:0000 0A 00000000:00000000 00:00000000 00000000 65532        0 1002 1 0000000000000000 100 0 0 10 0
+   2: 0A00000B:12D6 0A00000C:B3A2 01 00000000:00000000 00:00000000 00000000  1000        0 1003 1 0000000000000000 20 4 30 10 -1
+   3: 0A00000B:12D6 0A00000D:C1F0 01 00000000:00000000 00:00000000 00000000  1000        0 1004 1 0000000000000000 20 4 30 10 -1
+   4: 0A00000B:D2A4 0A01000A:0D3D 01 00000000:00000000 00:00000000 00000000  1000        0 1005 1 0000000000000000 20 4 30 10 -1
+   5: 0A00000B:D2A6 0A01000B:0016 01 00000000:00000000 00:00000000 00000000  1000        0 1006 1 0000000000000000 20 4 30 10 -1
+   6: 0A00000B:24BD 0A00000E:9C40 01 00000000:00000000 00:00000000 00000000 65532        0 1007 1 0000000000000000 20 4 30 10 -1
+   7: 0A00000B:12D6 0A00000F:A000 06 00000000:00000000 03:00000000 00000000     0        0 0 3 0000000000000000
+`
+
+func TestCollect(t *testing.T) {
+	sockets, err := ParseSockets(strings.NewReader(procNetTCP))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := Collect(sockets, defaultGuacdPort, 9405)
+	want := Stats{
+		Up:                true,
+		Connections:       2,
+		TargetConnections: map[int]int{22: 1, 3389: 1},
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+
+	var buf bytes.Buffer
+	WriteMetrics(&buf, got)
+
+	for _, line := range []string{
+		"guacd_up 1\n",
+		"guacd_connections 2\n",
+		"guacd_target_connections{port=\"22\"} 1\nguacd_target_connections{port=\"3389\"} 1\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, buf.String())
+		}
+	}
+
+	if _, err := ParseSockets(strings.NewReader("header\n 0: 00000000 00000000:0000 0A\n")); err == nil {
+		t.Error("expected error for invalid address")
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/guacamole-operator/guacamole-operator/containers/recording-retention
+
+go 1.26.3
+
+require github.com/google/go-cmp v0.7.0
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultRecordingDir string = "/recordings"
+
+func main() {
+	var root string
+	var maxAge time.Duration
+	var maxSize int64
+	var overrides string
+
+	flag.StringVar(&root, "dir", defaultRecordingDir, "Recording directory.")
+	flag.DurationVar(&maxAge, "max-age", 0, "Maximum age of recordings (0 disables).")
+	flag.Int64Var(&maxSize, "max-size", 0, "Maximum total size of recordings in bytes (0 disables).")
+	flag.StringVar(&overrides, "overrides", "", "Maximum age per connection as comma separated <name>=<duration> pairs.")
+	flag.Parse()
+
+	policy := Policy{
+		MaxAge:    maxAge,
+		MaxSize:   maxSize,
+		Overrides: map[string]time.Duration{},
+	}
+
+	for _, o := range strings.Split(overrides, ",") {
+		if o == "" {
+			continue
+		}
+
+		name, value, found := strings.Cut(o, "=")
+		if !found {
+			log.Fatalf("invalid override %q", o)
+		}
+
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("invalid override %q: %v", o, err)
+		}
+
+		policy.Overrides[name] = d
+	}
+
+	sweeper := &Sweeper{
+		Root:   root,
+		Policy: policy,
+	}
+
+	// Archive to S3 if a bucket is configured.
+	if bucket := os.Getenv("S3_BUCKET"); bucket != "" {
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+
+		sweeper.Archiver = &S3Archiver{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Bucket:          bucket,
+			Region:          region,
+			Prefix:          os.Getenv("S3_PREFIX"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		}
+	}
+
+	removed, err := sweeper.Sweep(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("Removed %d sessions.", removed)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Policy defines when recordings expire.
+type Policy struct {
+	// Maximum age of recordings. Zero disables the check.
+	MaxAge time.Duration
+	// Maximum total size of recordings in bytes. Zero disables the check.
+	MaxSize int64
+	// Maximum age per connection, keyed by recording name.
+	Overrides map[string]time.Duration
+}
+
+// Archiver stores a recording file before it is deleted.
+type Archiver interface {
+	Archive(ctx context.Context, key, path string) error
+}
+
+// session is a recorded session. The history recording storage extension
+// expects a directory per session, named by the history UUID.
+type session struct {
+	name       string
+	path       string
+	connection string
+	files      []string
+	size       int64
+	modTime    time.Time
+}
+
+// Sweeper enforces a retention policy on a recording directory.
+type Sweeper struct {
+	Root     string
+	Policy   Policy
+	Archiver Archiver
+	Now      func() time.Time
+}
+
+// Sweep removes expired recordings and returns the number of removed sessions.
+func (s *Sweeper) Sweep(ctx context.Context) (int, error) {
+	sessions, err := s.sessions()
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+
+	// Oldest sessions first.
+	slices.SortFunc(sessions, func(a, b session) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	var total int64
+	for _, sess := range sessions {
+		total += sess.size
+	}
+
+	removed := 0
+	var kept []session
+
+	for _, sess := range sessions {
+		maxAge := s.Policy.MaxAge
+		if override, ok := s.Policy.Overrides[sess.connection]; ok {
+			maxAge = override
+		}
+
+		if maxAge == 0 || now.Sub(sess.modTime) <= maxAge {
+			kept = append(kept, sess)
+			continue
+		}
+
+		log.Printf("Session %s expired (age %s).", sess.name, now.Sub(sess.modTime).Round(time.Second))
+		if err := s.remove(ctx, sess); err != nil {
+			return removed, err
+		}
+
+		total -= sess.size
+		removed++
+	}
+
+	if s.Policy.MaxSize == 0 {
+		return removed, nil
+	}
+
+	for _, sess := range kept {
+		if total <= s.Policy.MaxSize {
+			break
+		}
+
+		log.Printf("Session %s removed to satisfy size limit (total %d bytes).", sess.name, total)
+		if err := s.remove(ctx, sess); err != nil {
+			return removed, err
+		}
+
+		total -= sess.size
+		removed++
+	}
+
+	return removed, nil
+}
+
+// sessions returns all recorded sessions below the root directory.
+func (s *Sweeper) sessions() ([]session, error) {
+	entries, err := os.ReadDir(s.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []session
+
+	for _, entry := range entries {
+		// Skip hidden files like .snapshot directories.
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		sess := session{
+			name: entry.Name(),
+			path: filepath.Join(s.Root, entry.Name()),
+		}
+
+		err := filepath.WalkDir(sess.path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			if sess.connection == "" {
+				sess.connection = recordingName(d.Name())
+			}
+
+			sess.files = append(sess.files, path)
+			sess.size += info.Size()
+			if info.ModTime().After(sess.modTime) {
+				sess.modTime = info.ModTime()
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Empty directories expire by their own modification time.
+		if len(sess.files) == 0 {
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			sess.modTime = info.ModTime()
+		}
+
+		sessions = append(sessions, sess)
+	}
+
+	return sessions, nil
+}
+
+// remove archives and deletes a session.
+func (s *Sweeper) remove(ctx context.Context, sess session) error {
+	if s.Archiver != nil {
+		for _, file := range sess.files {
+			key, err := filepath.Rel(s.Root, file)
+			if err != nil {
+				return err
+			}
+
+			if err := s.Archiver.Archive(ctx, filepath.ToSlash(key), file); err != nil {
+				return fmt.Errorf("error archiving %s: %w", file, err)
+			}
+		}
+	}
+
+	return os.RemoveAll(sess.path)
+}
+
+// recordingName strips the numeric suffix guacd appends to
+// recordings of the same name, e.g. `recording.1`.
+func recordingName(file string) string {
+	ext := filepath.Ext(file)
+	if ext == "" {
+		return file
+	}
+
+	if strings.Trim(ext[1:], "0123456789") == "" {
+		return strings.TrimSuffix(file, ext)
+	}
+
+	return file
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var now = time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+// createSession creates a session directory with a single recording.
+func createSession(t *testing.T, root, name, recording string, size int, age time.Duration) {
+	t.Helper()
+
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(dir, recording)
+	if err := os.WriteFile(file, []byte(strings.Repeat("x", size)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	modTime := now.Add(-age)
+	if err := os.Chtimes(file, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func remaining(t *testing.T, root string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+func TestSweepMaxAge(t *testing.T) {
+	root := t.TempDir()
+	createSession(t, root, "a", "rdp", 10, 48*time.Hour)
+	createSession(t, root, "b", "rdp.1", 10, 1*time.Hour)
+	createSession(t, root, "c", "ssh", 10, 48*time.Hour)
+
+	sweeper := &Sweeper{
+		Root: root,
+		Policy: Policy{
+			MaxAge: 24 * time.Hour,
+			Overrides: map[string]time.Duration{
+				"ssh": 72 * time.Hour,
+			},
+		},
+		Now: func() time.Time { return now },
+	}
+
+	removed, err := sweeper.Sweep(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed != 1 {
+		t.Errorf("expected 1 removed session, got %d", removed)
+	}
+
+	want := []string{"b", "c"}
+	if got := remaining(t, root); !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestSweepMaxSize(t *testing.T) {
+	root := t.TempDir()
+	createSession(t, root, "a", "rdp", 100, 3*time.Hour)
+	createSession(t, root, "b", "rdp", 100, 2*time.Hour)
+	createSession(t, root, "c", "rdp", 100, 1*time.Hour)
+
+	sweeper := &Sweeper{
+		Root: root,
+		Policy: Policy{
+			MaxSize: 150,
+		},
+		Now: func() time.Time { return now },
+	}
+
+	if _, err := sweeper.Sweep(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"c"}
+	if got := remaining(t, root); !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+}
+
+// fakeS3 is a minimal stand-in for an S3-compatible object store.
+type fakeS3 struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut || !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.objects[r.URL.Path] = body
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestSweepArchive(t *testing.T) {
+	store := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(store)
+	defer server.Close()
+
+	root := t.TempDir()
+	createSession(t, root, "a", "rdp", 10, 48*time.Hour)
+
+	sweeper := &Sweeper{
+		Root: root,
+		Policy: Policy{
+			MaxAge: 24 * time.Hour,
+		},
+		Archiver: &S3Archiver{
+			Endpoint:        server.URL,
+			Bucket:          "recordings",
+			Region:          "us-east-1",
+			Prefix:          "guacamole",
+			AccessKeyID:     "key",
+			SecretAccessKey: "secret",
+		},
+		Now: func() time.Time { return now },
+	}
+
+	if _, err := sweeper.Sweep(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]byte{
+		"/recordings/guacamole/a/rdp": []byte(strings.Repeat("x", 10)),
+	}
+	if !cmp.Equal(want, store.objects) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, store.objects))
+	}
+
+	if got := remaining(t, root); len(got) != 0 {
+		t.Errorf("expected no remaining sessions, got %v", got)
+	}
+}
+
+func TestSweepArchiveFailureKeepsSession(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	createSession(t, root, "a", "rdp", 10, 48*time.Hour)
+
+	sweeper := &Sweeper{
+		Root:   root,
+		Policy: Policy{MaxAge: time.Hour},
+		Archiver: &S3Archiver{
+			Endpoint: server.URL,
+			Bucket:   "recordings",
+			Region:   "us-east-1",
+		},
+		Now: func() time.Time { return now },
+	}
+
+	if _, err := sweeper.Sweep(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+
+	want := []string{"a"}
+	if got := remaining(t, root); !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+}
@@ -45,6 +45,7 @@ import (
 	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
 	"github.com/guacamole-operator/guacamole-operator/internal/apierror"
 	guacclient "github.com/guacamole-operator/guacamole-operator/internal/client"
+	"github.com/guacamole-operator/guacamole-operator/internal/credentials"
 	reconciler "github.com/guacamole-operator/guacamole-operator/internal/reconciler/connection"
 )
 
@@ -56,6 +57,7 @@ type ConnectionReconciler struct {
 	GuacEventCh          <-chan GuacamoleWrappedEvent
 	Scheme               *runtime.Scheme
 	UsePriorityQueue     bool
+	Credentials          *credentials.Resolver
 }
 
 // +kubebuilder:rbac:groups=guacamole-operator.github.io,resources=connections,verbs=get;list;watch;create;update;patch;delete
@@ -64,7 +66,7 @@ type ConnectionReconciler struct {
 //
 // +kubebuilder:rbac:groups=guacamole-operator.github.io,resources=guacamoles,verbs=get;list
 //
-// +kubebuilder:rbac:groups="",resources=secrets;configmaps,verbs=get;list
+// +kubebuilder:rbac:groups="",resources=secrets;configmaps,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -94,8 +96,15 @@ func (r *ConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// Get corresponding Guacamole instance.
+	var guac v1alpha1.Guacamole
+	guacRef := types.NamespacedName{Name: connection.Spec.GuacamoleRef.Name, Namespace: connection.GetNamespace()}
+	if err := r.Get(ctx, guacRef, &guac); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Create Guacamole API client.
-	config, err := r.getConnectionParams(ctx, connection)
+	config, err := getConnectionParams(ctx, r.Client, &guac)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -114,7 +123,7 @@ func (r *ConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 
 	// Instantiate reconciler.
-	reconciler := reconciler.New(guacClient, r.GuacConcurrency)
+	reconciler := reconciler.New(guacClient, r.GuacConcurrency, &guac)
 
 	// Check if instance is marked to be deleted, which is
 	// indicated by the deletion timestamp being set. If so, process the
@@ -156,8 +165,22 @@ func (r *ConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// Resolve parameters from Secrets and credential providers.
+	params, ttl, err := r.Credentials.Resolve(ctx, &guac, connection)
+	if err != nil {
+		logger.Error(err, "Could not resolve parameters.")
+
+		connection.Status.MarkAsUnsynchronized()
+		if err := r.Status().Update(ctx, connection); err != nil {
+			logger.Error(err, "Failed to update status.")
+			return ctrl.Result{}, err
+		}
+
+		return ctrl.Result{}, err
+	}
+
 	// Sync state.
-	if err := reconciler.Sync(ctx, connection); err != nil {
+	if err := reconciler.Sync(ctx, connection, params); err != nil {
 		logger.Error(err, "Could not sync resource.")
 
 		connection.Status.MarkAsUnsynchronized()
@@ -184,16 +207,28 @@ func (r *ConnectionReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, err
 	}
 	logger.Info("Reconciled.")
-	return ctrl.Result{}, nil
+
+	// Synchronize again once resolved credentials expire,
+	// e.g. to pick up rotated passwords.
+	return ctrl.Result{RequeueAfter: ttl}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Credentials == nil {
+		r.Credentials = &credentials.Resolver{Client: mgr.GetClient()}
+	}
+
 	fieldToIndex, err := createGuacamoleIndexer(mgr)
 	if err != nil {
 		return err
 	}
 
+	secretFieldToIndex, err := createSecretIndexer(mgr)
+	if err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.Connection{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
 		Watches(
@@ -201,6 +236,11 @@ func (r *ConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			r.watchGuacamoleRef(fieldToIndex),
 			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
 		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.secretRequestMapFunc(secretFieldToIndex)),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		WatchesRawSource(
 			source.Channel(r.GuacEventCh, r.guacamoleEventHandler(fieldToIndex)),
 		).
@@ -238,6 +278,64 @@ func createGuacamoleIndexer(mgr ctrl.Manager) (string, error) {
 	return fieldToIndex, nil
 }
 
+// createSecretIndexer creates a local index of Secrets referenced by
+// parameter sources of Connections.
+func createSecretIndexer(mgr ctrl.Manager) (string, error) {
+	const fieldToIndex string = ".spec.parametersFrom.secretKeyRef.name"
+
+	indexerFunc := func(obj client.Object) []string {
+		connection, ok := obj.(*v1alpha1.Connection)
+		if !ok {
+			return nil
+		}
+
+		var names []string
+		for _, source := range connection.Spec.ParametersFrom {
+			if source.SecretKeyRef != nil && !slices.Contains(names, source.SecretKeyRef.Name) {
+				names = append(names, source.SecretKeyRef.Name)
+			}
+		}
+
+		return names
+	}
+
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &v1alpha1.Connection{}, fieldToIndex, indexerFunc)
+	if err != nil {
+		return "", err
+	}
+
+	return fieldToIndex, nil
+}
+
+// secretRequestMapFunc returns the Connections to be enqueued after an event
+// of a Secret referenced by their parameter sources.
+func (r *ConnectionReconciler) secretRequestMapFunc(indexField string) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		listOpts := &client.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector(indexField, obj.GetName()),
+			Namespace:     obj.GetNamespace(),
+		}
+
+		var connections v1alpha1.ConnectionList
+		if err := r.List(ctx, &connections, listOpts); err != nil {
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(connections.Items))
+
+		for _, c := range connections.Items {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      c.GetName(),
+					Namespace: c.GetNamespace(),
+				},
+			})
+		}
+
+		return requests
+	}
+}
+
 func (r *ConnectionReconciler) watchGuacamoleRef(indexField string) handler.EventHandler {
 	return handler.EnqueueRequestsFromMapFunc(r.guacamoleRequestMapFunc(indexField))
 }
@@ -325,16 +423,9 @@ func (r *ConnectionReconciler) guacamoleEventRequestMapFunc(indexField string) g
 }
 
 // getConnectionParams retrieves access parameters for the Guacamole API.
-func (r *ConnectionReconciler) getConnectionParams(ctx context.Context, obj *v1alpha1.Connection) (*guacclient.Config, error) {
-	namespace := obj.GetNamespace()
-
-	// Get corresponding Guacamole instance.
-	guacRef := obj.Spec.GuacamoleRef.Name
-
-	var guac v1alpha1.Guacamole
-	if err := r.Get(ctx, types.NamespacedName{Name: guacRef, Namespace: namespace}, &guac); err != nil {
-		return nil, err
-	}
+func getConnectionParams(ctx context.Context, c client.Reader, guac *v1alpha1.Guacamole) (*guacclient.Config, error) {
+	namespace := guac.GetNamespace()
+	guacRef := guac.GetName()
 
 	if guac.Status.Access == nil {
 		return nil, errors.New("access information missing")
@@ -354,7 +445,7 @@ func (r *ConnectionReconciler) getConnectionParams(ctx context.Context, obj *v1a
 		},
 	}
 
-	err := r.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, &secret)
+	err := c.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, &secret)
 	if err != nil && k8serrors.IsNotFound(err) {
 		return nil, fmt.Errorf("access parameters secret not found: %w", err)
 	}
@@ -19,8 +19,11 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -33,10 +36,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/addon"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/addon/pkg/loaders"
 	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/addon/pkg/status"
 	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative"
 
 	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+	"github.com/guacamole-operator/guacamole-operator/internal/channels"
+	guacclient "github.com/guacamole-operator/guacamole-operator/internal/client"
 	"github.com/guacamole-operator/guacamole-operator/internal/transformer"
 )
 
@@ -53,6 +59,10 @@ type GuacamoleReconciler struct {
 	Scheme         *runtime.Scheme
 	EnableListener bool
 	Listener       Listener
+	// Repository of the channel packages.
+	Channels loaders.Repository
+	// Registry mirror all images are rewritten to.
+	RegistryMirror string
 	watchLabels    declarative.LabelMaker
 }
 
@@ -67,8 +77,14 @@ type Listener interface {
 // +kubebuilder:rbac:groups=guacamole-operator.github.io,resources=guacamoles/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=guacamole-operator.github.io,resources=guacamoles/finalizers,verbs=update
 //
-// +kubebuilder:rbac:groups="",resources=services;serviceaccounts;secrets;configmaps,verbs=get;list;watch;create;update;delete;patch
+// +kubebuilder:rbac:groups="",resources=services;serviceaccounts;secrets;configmaps;persistentvolumeclaims,verbs=get;list;watch;create;update;delete;patch
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;delete;patch
+// +kubebuilder:rbac:groups=batch,resources=cronjobs;jobs,verbs=get;list;watch;create;update;delete;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete;patch
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;update;delete;patch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;delete;patch
 //
 // For WithApplyPrune.
 // +kubebuilder:rbac:groups=*,resources=*,verbs=list
@@ -77,7 +93,14 @@ type Listener interface {
 func (r *GuacamoleReconciler) setupReconciler(mgr ctrl.Manager) error {
 	r.watchLabels = declarative.SourceLabel(mgr.GetScheme())
 
+	repository := r.Channels
+	if repository == nil {
+		repository = channels.Bundled()
+	}
+
 	return r.Init(mgr, &v1alpha1.Guacamole{},
+		// Resolves versions honouring version pins and upgrade policies.
+		declarative.WithManifestController(&channels.ManifestLoader{Repository: repository, Client: mgr.GetClient()}),
 		declarative.WithOwner(declarative.SourceAsOwner),
 		declarative.WithLabels(r.watchLabels),
 		declarative.WithStatus(status.NewKstatusCheck(mgr.GetClient(), &r.Reconciler)),
@@ -86,6 +109,8 @@ func (r *GuacamoleReconciler) setupReconciler(mgr ctrl.Manager) error {
 		// made by Guacamole transformation.
 		declarative.WithObjectTransform(transformer.Guacd(mgr.GetClient()), addon.ApplyPatches),
 		declarative.WithObjectTransform(transformer.Guacamole(mgr.GetClient()), addon.ApplyPatches),
+		// Rewrites images of all components including patches to the registry mirror.
+		declarative.WithObjectTransform(transformer.Images(r.RegistryMirror)),
 		declarative.WithApplyKustomize(),
 	)
 }
@@ -115,9 +140,95 @@ func (r *GuacamoleReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	// Watch for changes to referenced secrets and config maps, e.g. rotated
+	// passwords or renewed certificates.
+	err = c.Watch(source.Kind(mgr.GetCache(), &corev1.Secret{}, handler.TypedEnqueueRequestsFromMapFunc(r.secretRequestMapFunc)))
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(source.Kind(mgr.GetCache(), &corev1.ConfigMap{}, handler.TypedEnqueueRequestsFromMapFunc(r.configMapRequestMapFunc)))
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// secretRequestMapFunc returns the Guacamole instances referencing a secret.
+func (r *GuacamoleReconciler) secretRequestMapFunc(ctx context.Context, secret *corev1.Secret) []reconcile.Request {
+	return r.referencingInstances(ctx, secret.GetNamespace(), func(spec *corev1.PodSpec) bool {
+		secrets, _ := transformer.PodReferences(spec)
+		return slices.Contains(secrets, secret.GetName())
+	})
+}
+
+// configMapRequestMapFunc returns the Guacamole instances referencing a config map,
+// either by their deployments or as branding content.
+func (r *GuacamoleReconciler) configMapRequestMapFunc(ctx context.Context, configMap *corev1.ConfigMap) []reconcile.Request {
+	requests := r.referencingInstances(ctx, configMap.GetNamespace(), func(spec *corev1.PodSpec) bool {
+		_, configMaps := transformer.PodReferences(spec)
+		return slices.Contains(configMaps, configMap.GetName())
+	})
+
+	var instances v1alpha1.GuacamoleList
+	if err := r.List(ctx, &instances, client.InNamespace(configMap.GetNamespace())); err != nil {
+		return requests
+	}
+
+	for _, instance := range instances.Items {
+		if instance.Spec.Branding == nil || !slices.Contains(instance.Spec.Branding.ConfigMapNames(), configMap.GetName()) {
+			continue
+		}
+
+		request := reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      instance.GetName(),
+				Namespace: instance.GetNamespace(),
+			},
+		}
+
+		if !slices.Contains(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests
+}
+
+// referencingInstances returns the Guacamole instances with deployments
+// matching a reference check. Deployments are mapped to their instance
+// by the label set by the declarative reconciler.
+func (r *GuacamoleReconciler) referencingInstances(ctx context.Context, namespace string, references func(*corev1.PodSpec) bool) []reconcile.Request {
+	labelKey := v1alpha1.GroupVersion.Group + "/guacamole"
+
+	var deployments appsv1.DeploymentList
+	if err := r.List(ctx, &deployments, client.InNamespace(namespace), client.HasLabels{labelKey}); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+
+	for _, deployment := range deployments.Items {
+		if !references(&deployment.Spec.Template.Spec) {
+			continue
+		}
+
+		request := reconcile.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      deployment.GetLabels()[labelKey],
+				Namespace: namespace,
+			},
+		}
+
+		if !slices.Contains(requests, request) {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests
+}
+
 // Reconcile runs the declarative.Reconciler logic and adds custom finalizer logic.
 func (r *GuacamoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
@@ -169,6 +280,13 @@ func (r *GuacamoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, err
 	}
 
+	// Reset TOTP enrollments of users.
+	if users, ok := instance.GetAnnotations()[v1alpha1.ResetTOTPAnnotation]; ok {
+		if err := r.resetTOTP(ctx, instance, users); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error resetting TOTP enrollments: %w", err)
+		}
+	}
+
 	// If instance is marked to have the cloudevents extension,
 	// add it to the listener instance.
 	_, ok := instance.GetAnnotations()["extension.guacamole-operator.github.io/cloudevents"]
@@ -184,6 +302,57 @@ func (r *GuacamoleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	return result, nil
 }
 
+// resetTOTP resets the TOTP enrollment of the users listed
+// in the reset annotation and removes the annotation afterwards.
+// Requests for instances without TOTP are reported via condition.
+func (r *GuacamoleReconciler) resetTOTP(ctx context.Context, obj *v1alpha1.Guacamole, users string) error {
+	// Status is updated by the declarative reconciler.
+	var instance v1alpha1.Guacamole
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), &instance); err != nil {
+		return err
+	}
+
+	// Retrying does not help until TOTP is configured.
+	if instance.Spec.Auth.MFA == nil || instance.Spec.Auth.MFA.TOTP == nil {
+		instance.Status.MarkTOTPNotConfigured()
+		return r.Status().Update(ctx, &instance)
+	}
+
+	config, err := getConnectionParams(ctx, r.Client, &instance)
+	if err != nil {
+		return err
+	}
+
+	guacClient, err := guacclient.New(config)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range strings.Split(users, ",") {
+		user = strings.TrimSpace(user)
+		if user == "" {
+			continue
+		}
+
+		if err := guacClient.ResetTOTP(ctx, user); err != nil {
+			return err
+		}
+
+		log.FromContext(ctx).Info("TOTP enrollment reset.", "user", user)
+	}
+
+	patch := client.MergeFrom(instance.DeepCopy())
+	delete(instance.Annotations, v1alpha1.ResetTOTPAnnotation)
+
+	if err := r.Patch(ctx, &instance, patch); err != nil {
+		return err
+	}
+
+	instance.Status.MarkTOTPReset(users)
+
+	return r.Status().Update(ctx, &instance)
+}
+
 // findWebSocketURL retrieves access parameters for the WebSocket API provided
 // by the custom Guacamole `cloudevents` extension.
 func (r *GuacamoleReconciler) findWebSocketURL(ctx context.Context, obj *v1alpha1.Guacamole) (string, error) {
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+	guacclient "github.com/guacamole-operator/guacamole-operator/internal/client"
+)
+
+const (
+	// metricsInterval is the default interval instances are queried.
+	metricsInterval = time.Minute
+	// metricsTimeout limits the API requests to an instance per refresh.
+	metricsTimeout = 10 * time.Second
+)
+
+var (
+	activeConnectionsDesc = prometheus.NewDesc(
+		"guacamole_active_connections",
+		"Active connections of the connections of a Guacamole connection group.",
+		[]string{"namespace", "guacamole", "group"}, nil,
+	)
+
+	// Names of connections are only unique within a group, the identifier
+	// keeps series unique.
+	connectionActiveConnectionsDesc = prometheus.NewDesc(
+		"guacamole_connection_active_connections",
+		"Active connections of a Guacamole connection.",
+		[]string{"namespace", "guacamole", "identifier", "connection", "group"}, nil,
+	)
+
+	usersOnlineDesc = prometheus.NewDesc(
+		"guacamole_users_online",
+		"Users with at least one active connection.",
+		[]string{"namespace", "guacamole"}, nil,
+	)
+
+	apiUpDesc = prometheus.NewDesc(
+		"guacamole_api_up",
+		"Whether the API of the Guacamole instance could be queried.",
+		[]string{"namespace", "guacamole"}, nil,
+	)
+)
+
+// MetricsCollector exposes gauges of the active connections of Guacamole
+// instances with metrics enabled. Instances are queried via their API in
+// an interval, scrapes of the operator metrics return the last results.
+type MetricsCollector struct {
+	Client client.Client
+	// Interval instances are queried, defaults to one minute.
+	Interval time.Duration
+
+	mutex   sync.RWMutex
+	metrics []prometheus.Metric
+}
+
+var _ prometheus.Collector = &MetricsCollector{}
+
+// Describe implements prometheus.Collector.
+func (c *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeConnectionsDesc
+	ch <- connectionActiveConnectionsDesc
+	ch <- usersOnlineDesc
+	ch <- apiUpDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	for _, metric := range c.metrics {
+		ch <- metric
+	}
+}
+
+// Start implements manager.Runnable. Refreshes the metrics in the interval.
+func (c *MetricsCollector) Start(ctx context.Context) error {
+	interval := c.Interval
+	if interval == 0 {
+		interval = metricsInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.refresh(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+// Only the leader queries instances to not count connections twice.
+func (c *MetricsCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// refresh queries all instances with metrics enabled and replaces
+// the metrics returned by Collect.
+func (c *MetricsCollector) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, metricsTimeout)
+	defer cancel()
+
+	logger := log.FromContext(ctx).WithName("metrics")
+
+	var instances v1alpha1.GuacamoleList
+	if err := c.Client.List(ctx, &instances); err != nil {
+		logger.Error(err, "Failed to list instances.")
+		return
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		metrics []prometheus.Metric
+	)
+
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if instance.Spec.Metrics == nil || instance.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			up := 1.0
+
+			instanceMetrics, err := c.collectInstance(ctx, instance)
+			if err != nil {
+				logger.Info("Failed to collect metrics of instance.", "namespace", instance.Namespace, "name", instance.Name, "error", err.Error())
+				up = 0
+			}
+
+			instanceMetrics = append(instanceMetrics,
+				prometheus.MustNewConstMetric(apiUpDesc, prometheus.GaugeValue, up, instance.Namespace, instance.Name))
+
+			mutex.Lock()
+			metrics = append(metrics, instanceMetrics...)
+			mutex.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	c.mutex.Lock()
+	c.metrics = metrics
+	c.mutex.Unlock()
+}
+
+// collectInstance queries the active connections of an instance.
+func (c *MetricsCollector) collectInstance(ctx context.Context, instance *v1alpha1.Guacamole) ([]prometheus.Metric, error) {
+	config, err := getConnectionParams(ctx, c.Client, instance)
+	if err != nil {
+		return nil, err
+	}
+
+	guacClient, err := guacclient.New(config)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := guacClient.ActiveConnectionStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return statsMetrics(instance, stats), nil
+}
+
+// statsMetrics returns the metrics of the active connections of an instance.
+// Series per connection are only returned if enabled for the instance.
+func statsMetrics(instance *v1alpha1.Guacamole, stats *guacclient.ActiveConnectionStats) []prometheus.Metric {
+	metrics := make([]prometheus.Metric, 0, len(stats.Groups)+1)
+
+	for _, group := range stats.Groups {
+		metrics = append(metrics, prometheus.MustNewConstMetric(activeConnectionsDesc, prometheus.GaugeValue, float64(group.Active),
+			instance.Namespace, instance.Name, group.Group))
+	}
+
+	if instance.Spec.Metrics.PerConnection {
+		for _, connection := range stats.Connections {
+			metrics = append(metrics, prometheus.MustNewConstMetric(connectionActiveConnectionsDesc, prometheus.GaugeValue, float64(connection.Active),
+				instance.Namespace, instance.Name, connection.Identifier, connection.Name, connection.Group))
+		}
+	}
+
+	metrics = append(metrics, prometheus.MustNewConstMetric(usersOnlineDesc, prometheus.GaugeValue, float64(stats.Users), instance.Namespace, instance.Name))
+
+	return metrics
+}
@@ -0,0 +1,75 @@
+#!/usr/bin/env bash
+# Checks the pinned tags of images built from containers/. Every image
+# carries a release version in containers/<name>/VERSION, which its
+# workflow publishes as tag on merge. The operator must pin exactly that
+# tag, so bumping VERSION lands the image and the pin together.
+#
+# Usage: check-images.sh [--registry] <name>...
+#
+#   --registry  additionally verify that the pinned tags exist in the
+#               registry (requires skopeo).
+#
+# With BASE_REF set, changes to containers/<name> since BASE_REF must bump
+# VERSION, as published release tags are never overwritten.
+#
+# All other images referenced by the operator must be pinned to a version,
+# floating tags like `latest` or `alpine` are rejected.
+set -euo pipefail
+
+registry=false
+if [ "${1:-}" = "--registry" ]; then
+	registry=true
+	shift
+fi
+
+status=0
+
+for ref in $(grep -rhoE --include='*.go' --exclude='*_test.go' '"[a-z0-9.-]+\.[a-z]+/[^" ]+:[^" ]+"' internal/ | tr -d '"' | sort -u); do
+	tag=${ref##*:}
+
+	if [ "${tag}" = "latest" ] || [[ ! "${tag}" =~ [0-9] ]]; then
+		echo "${ref}: pin a version instead of the floating tag ${tag}" >&2
+		status=1
+	fi
+done
+
+for name in "$@"; do
+	version_file="containers/${name}/VERSION"
+	if [ ! -f "${version_file}" ]; then
+		echo "${name}: ${version_file} not found" >&2
+		status=1
+		continue
+	fi
+	version=$(tr -d '[:space:]' <"${version_file}")
+
+	refs=$(grep -rhoE "ghcr\.io/guacamole-operator/${name}:[A-Za-z0-9._-]+" internal/ | sort -u)
+	if [ -z "${refs}" ]; then
+		echo "${name}: image reference not found" >&2
+		status=1
+		continue
+	fi
+
+	for ref in ${refs}; do
+		tag=${ref##*:}
+
+		if [ "${tag}" != "${version}" ]; then
+			echo "${ref}: pin the released version ${version} from ${version_file}" >&2
+			status=1
+			continue
+		fi
+
+		if ${registry} && ! skopeo inspect --raw "docker://${ref}" >/dev/null; then
+			echo "${ref}: tag not found in the registry" >&2
+			status=1
+		fi
+	done
+
+	if [ -n "${BASE_REF:-}" ] &&
+		! git diff --quiet "${BASE_REF}" HEAD -- "containers/${name}" &&
+		git diff --quiet "${BASE_REF}" HEAD -- "${version_file}"; then
+		echo "${name}: containers/${name} changed, bump ${version_file}" >&2
+		status=1
+	fi
+done
+
+exit ${status}
@@ -0,0 +1,202 @@
+// Package branding builds Guacamole extensions customizing the appearance
+// of the web application.
+package branding
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Namespace of the generated extension. Resources are served
+// below `app/ext/<namespace>/`.
+const Namespace = "guacamole-operator-branding"
+
+// Languages of the translations bundled with Guacamole. The title is set
+// in all of them, so that it does not depend on the language of users.
+var bundledLanguages = []string{
+	"ca", "cs", "de", "en", "es", "fr", "it", "ja", "ko", "nl", "no", "pl", "pt", "ru", "zh",
+}
+
+// Modification time of all archive entries, so that equal content
+// results in equal archives.
+var modified = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// File is a named file of an extension.
+type File struct {
+	// Name of the file, used to derive the file extension and media type.
+	Name string
+	// Content of the file.
+	Data []byte
+}
+
+// Content defines the content of a branding extension.
+type Content struct {
+	// Name of the application shown in the title and on the login page.
+	Title string
+	// Logo shown on the login page.
+	Logo *File
+	// Background image of the login page.
+	Background *File
+	// Additional style sheet.
+	CSS string
+	// Translation files (JSON) by language key, e.g. `en`.
+	Translations map[string][]byte
+}
+
+// manifest is the `guac-manifest.json` of an extension.
+type manifest struct {
+	GuacamoleVersion string            `json:"guacamoleVersion"`
+	Name             string            `json:"name"`
+	Namespace        string            `json:"namespace"`
+	CSS              []string          `json:"css,omitempty"`
+	Translations     []string          `json:"translations,omitempty"`
+	Resources        map[string]string `json:"resources,omitempty"`
+}
+
+// Build returns the extension archive (`.jar`) of the content.
+// The archive is deterministic.
+func Build(content *Content) ([]byte, error) {
+	files := map[string][]byte{}
+	m := manifest{
+		GuacamoleVersion: "*",
+		Name:             "Branding",
+		Namespace:        Namespace,
+		Resources:        map[string]string{},
+	}
+
+	var css strings.Builder
+
+	if content.Logo != nil {
+		name := "images/logo" + path.Ext(content.Logo.Name)
+		files[name] = content.Logo.Data
+		m.Resources[name] = mediaType(name)
+
+		fmt.Fprintf(&css, ".login-ui .login-dialog .logo {\n    background-image: url('app/ext/%s/%s');\n}\n", Namespace, name)
+	}
+
+	if content.Background != nil {
+		name := "images/background" + path.Ext(content.Background.Name)
+		files[name] = content.Background.Data
+		m.Resources[name] = mediaType(name)
+
+		fmt.Fprintf(&css, ".login-ui {\n    background-image: url('app/ext/%s/%s');\n    background-size: cover;\n}\n", Namespace, name)
+	}
+
+	css.WriteString(content.CSS)
+
+	if css.Len() > 0 {
+		files["branding.css"] = []byte(css.String())
+		m.CSS = []string{"branding.css"}
+	}
+
+	translations, err := mergeTitle(content.Translations, content.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, language := range slices.Sorted(maps.Keys(translations)) {
+		name := "translations/" + language + ".json"
+		files[name] = translations[language]
+		m.Translations = append(m.Translations, name)
+	}
+
+	manifestData, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+
+	files["guac-manifest.json"] = manifestData
+
+	return archive(files)
+}
+
+// mergeTitle sets the application name in the bundled translations and
+// all given ones.
+func mergeTitle(translations map[string][]byte, title string) (map[string][]byte, error) {
+	merged := maps.Clone(translations)
+	if merged == nil {
+		merged = map[string][]byte{}
+	}
+
+	if title == "" {
+		return merged, nil
+	}
+
+	languages := slices.Clone(bundledLanguages)
+	for language := range merged {
+		if !slices.Contains(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+
+	for _, language := range languages {
+		translation := map[string]any{}
+		if data, ok := merged[language]; ok {
+			if err := json.Unmarshal(data, &translation); err != nil {
+				return nil, fmt.Errorf("error parsing translation %s: %w", language, err)
+			}
+		}
+
+		app, _ := translation["APP"].(map[string]any)
+		if app == nil {
+			app = map[string]any{}
+		}
+
+		app["NAME"] = title
+		translation["APP"] = app
+
+		data, err := json.MarshalIndent(translation, "", "    ")
+		if err != nil {
+			return nil, err
+		}
+
+		merged[language] = data
+	}
+
+	return merged, nil
+}
+
+// archive returns a zip archive of the files in order of their names.
+func archive(files map[string][]byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := zip.NewWriter(&buf)
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		f, err := w.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := f.Write(files[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// mediaType returns the media type of a file by its extension.
+func mediaType(name string) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		return t
+	}
+
+	return "application/octet-stream"
+}
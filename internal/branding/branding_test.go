@@ -0,0 +1,129 @@
+package branding
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuild(t *testing.T) {
+	content := &Content{
+		Title:      "Remote Access",
+		Logo:       &File{Name: "logo.svg", Data: []byte("<svg/>")},
+		Background: &File{Name: "background.png", Data: []byte{0x89, 'P', 'N', 'G'}},
+		CSS:        ".login-ui { color: red; }\n",
+		Translations: map[string][]byte{
+			"en": []byte(`{"LOGIN": {"INFO_LOGIN_REQUIRED": "Welcome"}}`),
+			"de": []byte(`{"LOGIN": {"INFO_LOGIN_REQUIRED": "Willkommen"}}`),
+		},
+	}
+
+	data, err := Build(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Archives are deterministic.
+	again, err := Build(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(data, again) {
+		t.Error("expected equal archives for equal content")
+	}
+
+	files := readArchive(t, data)
+
+	var got manifest
+	if err := json.Unmarshal(files["guac-manifest.json"], &got); err != nil {
+		t.Fatal(err)
+	}
+
+	// The title is set in all bundled translations.
+	var translations []string
+	for _, language := range bundledLanguages {
+		translations = append(translations, "translations/"+language+".json")
+	}
+
+	want := manifest{
+		GuacamoleVersion: "*",
+		Name:             "Branding",
+		Namespace:        Namespace,
+		CSS:              []string{"branding.css"},
+		Translations:     translations,
+		Resources: map[string]string{
+			"images/background.png": "image/png",
+			"images/logo.svg":       "image/svg+xml",
+		},
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+
+	var en map[string]map[string]string
+	if err := json.Unmarshal(files["translations/en.json"], &en); err != nil {
+		t.Fatal(err)
+	}
+
+	wantEN := map[string]map[string]string{
+		"APP":   {"NAME": "Remote Access"},
+		"LOGIN": {"INFO_LOGIN_REQUIRED": "Welcome"},
+	}
+
+	if !cmp.Equal(wantEN, en) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantEN, en))
+	}
+
+	var fr map[string]map[string]string
+	if err := json.Unmarshal(files["translations/fr.json"], &fr); err != nil {
+		t.Fatal(err)
+	}
+
+	wantFR := map[string]map[string]string{
+		"APP": {"NAME": "Remote Access"},
+	}
+
+	if !cmp.Equal(wantFR, fr) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantFR, fr))
+	}
+
+	for _, name := range []string{"images/logo.svg", "images/background.png", "branding.css", "translations/de.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected file %s in archive", name)
+		}
+	}
+}
+
+func readArchive(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{}
+
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		files[f.Name] = content
+	}
+
+	return files
+}
@@ -0,0 +1,152 @@
+// Package channels resolves the package versions of Guacamole instances
+// and loads their manifests from the channels.
+package channels
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/addon/pkg/loaders"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+	"github.com/guacamole-operator/guacamole-operator/internal/transformer"
+)
+
+// defaultChannel is used if an instance does not specify a channel.
+const defaultChannel = "stable"
+
+// ManifestLoader resolves the package version of an instance, honouring
+// its version pin and upgrade policy, and loads the manifest of the
+// version. The current and available versions are set in the status.
+type ManifestLoader struct {
+	Repository loaders.Repository
+	// Client to read the deployed version if the status has none.
+	Client client.Reader
+}
+
+var _ declarative.ManifestController = &ManifestLoader{}
+
+// ResolveManifest implements declarative.ManifestController.
+func (l *ManifestLoader) ResolveManifest(ctx context.Context, object runtime.Object) (map[string]string, error) {
+	guac, ok := object.(*v1alpha1.Guacamole)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", object)
+	}
+
+	version, err := l.resolveVersion(ctx, guac)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := l.Repository.LoadManifest(ctx, guac.ComponentName(), version)
+	if err != nil {
+		return nil, fmt.Errorf("error loading manifest of version %s: %w", version, err)
+	}
+
+	guac.Status.Version = version
+
+	return files, nil
+}
+
+// resolveVersion returns the package version of an instance. A pinned
+// version takes precedence. Otherwise the latest version of the channel
+// is used, unless the upgrade policy is manual and the upgrade from the
+// current version was not approved yet.
+func (l *ManifestLoader) resolveVersion(ctx context.Context, guac *v1alpha1.Guacamole) (string, error) {
+	logger := log.FromContext(ctx)
+
+	channelName := guac.Spec.Channel
+	if channelName == "" {
+		channelName = defaultChannel
+	}
+
+	latest, err := l.latestVersion(ctx, guac.ComponentName(), channelName)
+
+	// Pinned versions do not depend on the channel.
+	if guac.Spec.Version != "" {
+		if err != nil {
+			logger.Info("error resolving available version", "channel", channelName, "error", err.Error())
+		}
+
+		guac.Status.AvailableVersion = latest
+
+		return guac.Spec.Version, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	guac.Status.AvailableVersion = latest
+
+	current := guac.Status.Version
+
+	// Instances with a lost status keep their deployed version.
+	if current == "" && guac.Spec.UpgradePolicy == v1alpha1.UpgradePolicyManual {
+		current, err = l.deployedVersion(ctx, guac)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	switch {
+	case current == "" || current == latest:
+		return latest, nil
+	case guac.Spec.UpgradePolicy != v1alpha1.UpgradePolicyManual:
+		logger.Info("upgrading to latest version of channel", "channel", channelName, "from", current, "to", latest)
+		return latest, nil
+	case guac.GetAnnotations()[v1alpha1.ApproveUpgradeAnnotation] == latest:
+		logger.Info("upgrading to approved version", "channel", channelName, "from", current, "to", latest)
+		return latest, nil
+	default:
+		logger.Info("upgrade not approved, keeping current version", "channel", channelName, "current", current, "available", latest)
+		return current, nil
+	}
+}
+
+// latestVersion returns the latest version of a package in a channel.
+func (l *ManifestLoader) latestVersion(ctx context.Context, packageName, channelName string) (string, error) {
+	channel, err := l.Repository.LoadChannel(ctx, channelName)
+	if err != nil {
+		return "", err
+	}
+
+	latest, err := channel.Latest(ctx, packageName)
+	if err != nil {
+		return "", err
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("could not find latest version in channel %q", channelName)
+	}
+
+	return latest.Version, nil
+}
+
+// deployedVersion returns the package version recorded on the deployment
+// of an instance or an empty string if it is not deployed.
+func (l *ManifestLoader) deployedVersion(ctx context.Context, guac *v1alpha1.Guacamole) (string, error) {
+	if l.Client == nil {
+		return "", nil
+	}
+
+	var deployment appsv1.Deployment
+
+	key := types.NamespacedName{Name: transformer.GuacamoleDeploymentName + "-" + guac.Name, Namespace: guac.Namespace}
+	if err := l.Client.Get(ctx, key, &deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("error getting deployed version: %w", err)
+	}
+
+	return deployment.Labels[v1alpha1.PackageVersionLabel], nil
+}
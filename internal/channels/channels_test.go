@@ -0,0 +1,141 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	addonv1alpha1 "sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/addon/pkg/apis/v1alpha1"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/addon/pkg/loaders"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+// repository is a repository with a single channel.
+type repository struct {
+	versions []string
+}
+
+func (r *repository) LoadChannel(_ context.Context, name string) (*loaders.Channel, error) {
+	if name != "stable" {
+		return nil, fmt.Errorf("channel %q not found", name)
+	}
+
+	channel := &loaders.Channel{}
+	for _, v := range r.versions {
+		channel.Manifests = append(channel.Manifests, loaders.Version{Version: v})
+	}
+
+	return channel, nil
+}
+
+func (r *repository) LoadManifest(_ context.Context, packageName, id string) (map[string]string, error) {
+	return map[string]string{packageName + "/" + id: ""}, nil
+}
+
+func TestResolveManifest(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          v1alpha1.GuacamoleSpec
+		annotations   map[string]string
+		current       string
+		deployed      string
+		wantVersion   string
+		wantAvailable string
+	}{
+		{
+			name:          "new instance",
+			wantVersion:   "0.0.2",
+			wantAvailable: "0.0.2",
+		},
+		{
+			name:          "automatic upgrade",
+			current:       "0.0.1",
+			wantVersion:   "0.0.2",
+			wantAvailable: "0.0.2",
+		},
+		{
+			name:          "manual upgrade pending",
+			spec:          v1alpha1.GuacamoleSpec{UpgradePolicy: v1alpha1.UpgradePolicyManual},
+			current:       "0.0.1",
+			wantVersion:   "0.0.1",
+			wantAvailable: "0.0.2",
+		},
+		{
+			name:          "manual upgrade approved",
+			spec:          v1alpha1.GuacamoleSpec{UpgradePolicy: v1alpha1.UpgradePolicyManual},
+			annotations:   map[string]string{v1alpha1.ApproveUpgradeAnnotation: "0.0.2"},
+			current:       "0.0.1",
+			wantVersion:   "0.0.2",
+			wantAvailable: "0.0.2",
+		},
+		{
+			// Status lost, e.g. after a restore from backup.
+			name:          "manual upgrade with deployed version",
+			spec:          v1alpha1.GuacamoleSpec{UpgradePolicy: v1alpha1.UpgradePolicyManual},
+			deployed:      "0.0.1",
+			wantVersion:   "0.0.1",
+			wantAvailable: "0.0.2",
+		},
+		{
+			name:          "pinned version",
+			spec:          v1alpha1.GuacamoleSpec{CommonSpec: addonv1alpha1.CommonSpec{Version: "0.0.1"}},
+			current:       "0.0.2",
+			wantVersion:   "0.0.1",
+			wantAvailable: "0.0.2",
+		},
+		{
+			name:        "pinned version without channel",
+			spec:        v1alpha1.GuacamoleSpec{CommonSpec: addonv1alpha1.CommonSpec{Version: "0.0.1", Channel: "unknown"}},
+			wantVersion: "0.0.1",
+		},
+	}
+
+	loader := &ManifestLoader{Repository: &repository{versions: []string{"0.0.1", "0.0.2"}}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder()
+			if tt.deployed != "" {
+				builder.WithObjects(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+					Name:      "guacamole-example",
+					Namespace: "default",
+					Labels:    map[string]string{v1alpha1.PackageVersionLabel: tt.deployed},
+				}})
+			}
+
+			loader := &ManifestLoader{Repository: loader.Repository, Client: builder.Build()}
+
+			guac := &v1alpha1.Guacamole{
+				ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default", Annotations: tt.annotations},
+				Spec:       tt.spec,
+				Status:     v1alpha1.GuacamoleStatus{Version: tt.current},
+			}
+
+			files, err := loader.ResolveManifest(context.Background(), guac)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, ok := files["guacamole/"+tt.wantVersion]; !ok {
+				t.Errorf("expected manifest of version %s, got %v", tt.wantVersion, files)
+			}
+
+			if guac.Status.Version != tt.wantVersion {
+				t.Errorf("status.version = %q, want %q", guac.Status.Version, tt.wantVersion)
+			}
+
+			if guac.Status.AvailableVersion != tt.wantAvailable {
+				t.Errorf("status.availableVersion = %q, want %q", guac.Status.AvailableVersion, tt.wantAvailable)
+			}
+		})
+	}
+
+	// Channels without versions fail unless a version is pinned.
+	if _, err := loader.ResolveManifest(context.Background(), &v1alpha1.Guacamole{Spec: v1alpha1.GuacamoleSpec{CommonSpec: addonv1alpha1.CommonSpec{Channel: "unknown"}}}); err == nil {
+		t.Error("expected error for unknown channel")
+	}
+}
@@ -0,0 +1,197 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/addon/pkg/loaders"
+	"sigs.k8s.io/yaml"
+
+	bundled "github.com/guacamole-operator/guacamole-operator/channels"
+)
+
+// configMapKeySeparator replaces the path separator in the keys of
+// a channels ConfigMap, e.g. `packages_guacamole_0.0.2_guacamole.yaml`.
+const configMapKeySeparator = "_"
+
+// NewRepository returns the repository of the channel packages. Packages
+// are loaded from a ConfigMap (`namespace/name`) or a location (directory,
+// HTTP(S) URL or git repository) if set, otherwise from the packages
+// bundled into the binary.
+func NewRepository(reader client.Reader, location, configMap string) (loaders.Repository, error) {
+	switch {
+	case configMap != "" && location != "":
+		return nil, fmt.Errorf("only one of a channels location or ConfigMap can be set")
+	case configMap != "":
+		namespace, name, ok := strings.Cut(configMap, "/")
+		if !ok || namespace == "" || name == "" {
+			return nil, fmt.Errorf("invalid channels ConfigMap %q, expected namespace/name", configMap)
+		}
+
+		return &ConfigMapRepository{Reader: reader, Key: types.NamespacedName{Namespace: namespace, Name: name}}, nil
+	case strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://"):
+		return loaders.NewHTTPRepository(location), nil
+	case strings.Contains(location, "git//") || strings.Contains(location, ".git"):
+		return loaders.NewGitRepository(location), nil
+	case location != "":
+		return loaders.NewFSRepository(location), nil
+	default:
+		return Bundled(), nil
+	}
+}
+
+// Bundled returns the repository of the packages bundled into the binary.
+func Bundled() loaders.Repository {
+	return &FSRepository{FS: bundled.FS}
+}
+
+// FSRepository loads channels and packages from a file system,
+// e.g. the packages bundled into the binary.
+type FSRepository struct {
+	FS fs.FS
+}
+
+var _ loaders.Repository = &FSRepository{}
+
+// LoadChannel implements loaders.Repository.
+func (r *FSRepository) LoadChannel(_ context.Context, name string) (*loaders.Channel, error) {
+	if !allowedName(name) {
+		return nil, fmt.Errorf("invalid channel name: %q", name)
+	}
+
+	data, err := fs.ReadFile(r.FS, name)
+	if err != nil {
+		return nil, fmt.Errorf("error reading channel %s: %w", name, err)
+	}
+
+	return parseChannel(name, data)
+}
+
+// LoadManifest implements loaders.Repository.
+func (r *FSRepository) LoadManifest(_ context.Context, packageName, id string) (map[string]string, error) {
+	if !allowedName(packageName) || !allowedName(id) {
+		return nil, fmt.Errorf("invalid package %q or version %q", packageName, id)
+	}
+
+	dir := path.Join("packages", packageName, id)
+
+	entries, err := fs.ReadDir(r.FS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory %s: %w", dir, err)
+	}
+
+	files := map[string]string{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		file := path.Join(dir, entry.Name())
+
+		data, err := fs.ReadFile(r.FS, file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading file %s: %w", file, err)
+		}
+
+		files[file] = string(data)
+	}
+
+	return files, nil
+}
+
+// ConfigMapRepository loads channels and packages from a ConfigMap. Channels
+// are stored by name, files of packages by their path with separator `_`,
+// e.g. `packages_guacamole_0.0.2_kustomization.yaml`.
+type ConfigMapRepository struct {
+	Reader client.Reader
+	Key    types.NamespacedName
+}
+
+var _ loaders.Repository = &ConfigMapRepository{}
+
+// LoadChannel implements loaders.Repository.
+func (r *ConfigMapRepository) LoadChannel(ctx context.Context, name string) (*loaders.Channel, error) {
+	if !allowedName(name) {
+		return nil, fmt.Errorf("invalid channel name: %q", name)
+	}
+
+	data, err := r.data(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, ok := data[name]
+	if !ok {
+		return nil, fmt.Errorf("channel %s not found in ConfigMap %s", name, r.Key)
+	}
+
+	return parseChannel(name, []byte(channel))
+}
+
+// LoadManifest implements loaders.Repository.
+func (r *ConfigMapRepository) LoadManifest(ctx context.Context, packageName, id string) (map[string]string, error) {
+	if !allowedName(packageName) || !allowedName(id) {
+		return nil, fmt.Errorf("invalid package %q or version %q", packageName, id)
+	}
+
+	data, err := r.data(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.Join([]string{"packages", packageName, id, ""}, configMapKeySeparator)
+	files := map[string]string{}
+
+	for key, value := range data {
+		if name, ok := strings.CutPrefix(key, prefix); ok && name != "" {
+			files[path.Join("packages", packageName, id, name)] = value
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("package %s version %s not found in ConfigMap %s", packageName, id, r.Key)
+	}
+
+	return files, nil
+}
+
+func (r *ConfigMapRepository) data(ctx context.Context) (map[string]string, error) {
+	var configMap corev1.ConfigMap
+	if err := r.Reader.Get(ctx, r.Key, &configMap); err != nil {
+		return nil, fmt.Errorf("error getting channels ConfigMap %s: %w", r.Key, err)
+	}
+
+	return configMap.Data, nil
+}
+
+func parseChannel(name string, data []byte) (*loaders.Channel, error) {
+	channel := &loaders.Channel{}
+	if err := yaml.Unmarshal(data, channel); err != nil {
+		return nil, fmt.Errorf("error parsing channel %s: %w", name, err)
+	}
+
+	return channel, nil
+}
+
+// allowedName returns whether a channel, package or version name is safe
+// to use in paths.
+func allowedName(name string) bool {
+	if name == "" || strings.HasPrefix(name, ".") {
+		return false
+	}
+
+	for _, c := range name {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' && c != '.' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,128 @@
+package channels
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBundled(t *testing.T) {
+	repository := Bundled()
+
+	channel, err := repository.LoadChannel(context.Background(), defaultChannel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := channel.Latest(context.Background(), "guacamole")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if latest == nil {
+		t.Fatal("expected latest version in bundled channel")
+	}
+
+	files, err := repository.LoadManifest(context.Background(), "guacamole", latest.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := files["packages/guacamole/"+latest.Version+"/kustomization.yaml"]; !ok {
+		t.Errorf("expected kustomization in bundled package, got %v", len(files))
+	}
+
+	if _, err := repository.LoadManifest(context.Background(), "guacamole", "../stable"); err == nil {
+		t.Error("expected error for invalid version")
+	}
+}
+
+func TestConfigMapRepository(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "channels", Namespace: "guacamole-operator-system"},
+		Data: map[string]string{
+			"stable": "manifests:\n- version: 0.1.0\n",
+			"packages_guacamole_0.1.0_kustomization.yaml": "resources:\n- guacamole.yaml\n",
+			"packages_guacamole_0.1.0_guacamole.yaml":     "kind: Deployment\n",
+			"packages_guacamole_0.2.0_guacamole.yaml":     "kind: Deployment\n",
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(configMap).Build()
+
+	repository, err := NewRepository(c, "", "guacamole-operator-system/channels")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channel, err := repository.LoadChannel(context.Background(), "stable")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := channel.Latest(context.Background(), "guacamole")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if latest == nil || latest.Version != "0.1.0" {
+		t.Fatalf("unexpected latest version %v", latest)
+	}
+
+	got, err := repository.LoadManifest(context.Background(), "guacamole", "0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"packages/guacamole/0.1.0/kustomization.yaml": "resources:\n- guacamole.yaml\n",
+		"packages/guacamole/0.1.0/guacamole.yaml":     "kind: Deployment\n",
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+
+	if _, err := repository.LoadChannel(context.Background(), "dev"); err == nil {
+		t.Error("expected error for missing channel")
+	}
+
+	if _, err := repository.LoadManifest(context.Background(), "guacamole", "0.3.0"); err == nil {
+		t.Error("expected error for missing package")
+	}
+
+	missing := &ConfigMapRepository{Reader: c, Key: types.NamespacedName{Namespace: "default", Name: "channels"}}
+	if _, err := missing.LoadChannel(context.Background(), "stable"); err == nil {
+		t.Error("expected error for missing ConfigMap")
+	}
+}
+
+func TestNewRepository(t *testing.T) {
+	tests := []struct {
+		name      string
+		location  string
+		configMap string
+		wantErr   bool
+	}{
+		{name: "bundled"},
+		{name: "directory", location: "./channels"},
+		{name: "http", location: "https://example.com/channels"},
+		{name: "configmap", configMap: "default/channels"},
+		{name: "invalid configmap", configMap: "channels", wantErr: true},
+		{name: "location and configmap", location: "./channels", configMap: "default/channels", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRepository(nil, tt.location, tt.configMap)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRepository() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -97,6 +99,54 @@ func authenticate(client loginClient) gen.RequestEditorFn {
 	}
 }
 
+// ResetTOTP resets the TOTP enrollment of a user.
+// The user has to enroll again with the next login.
+func (c *Client) ResetTOTP(ctx context.Context, username string) error {
+	response, err := c.GetUserWithResponse(ctx, c.Source, username)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode() != http.StatusOK {
+		return &apierror.APIError{
+			Err: fmt.Errorf("could not get user %s", username),
+		}
+	}
+
+	// Attributes of the TOTP extension are not part of the generated model,
+	// so the user is updated as is with the reset attribute added.
+	var user map[string]any
+	if err := json.Unmarshal(response.Body, &user); err != nil {
+		return err
+	}
+
+	attributes, ok := user["attributes"].(map[string]any)
+	if !ok {
+		attributes = map[string]any{}
+	}
+
+	attributes["guac-totp-reset"] = "true"
+	user["attributes"] = attributes
+
+	body, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	update, err := c.UpdateUserWithBodyWithResponse(ctx, c.Source, username, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if update.StatusCode() != http.StatusNoContent {
+		return &apierror.APIError{
+			Err: fmt.Errorf("could not reset TOTP enrollment of user %s", username),
+		}
+	}
+
+	return nil
+}
+
 // resolveConnectionGroup resolves a connection group path to the internal identifier.
 // Missing groups will be created automatically. Returns the direct parent identifier
 // and a list of all parent connection groups.
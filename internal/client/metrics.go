@@ -0,0 +1,151 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/guacamole-operator/guacamole-operator/internal/apierror"
+	"github.com/guacamole-operator/guacamole-operator/internal/client/gen"
+)
+
+// ActiveConnectionStats summarizes the active connections of an instance.
+type ActiveConnectionStats struct {
+	// Active connections per connection, including idle connections.
+	Connections []ConnectionStats
+	// Active connections per connection group, including idle groups
+	// containing connections.
+	Groups []GroupStats
+	// Users with at least one active connection.
+	Users int
+}
+
+// GroupStats holds the active connections of the connections of a group.
+type GroupStats struct {
+	// Path of the group in the format of connection resources.
+	Group  string
+	Active int
+}
+
+// ConnectionStats holds the active connections of a connection.
+type ConnectionStats struct {
+	// Identifier of the connection, unique within the instance.
+	Identifier string
+	Name       string
+	// Path of the parent group in the format of connection
+	// resources (/<group>/<group>).
+	Group  string
+	Active int
+}
+
+// ActiveConnectionStats returns the active connections per connection
+// and the number of users with active connections.
+func (c *Client) ActiveConnectionStats(ctx context.Context) (*ActiveConnectionStats, error) {
+	active, err := c.ListActiveConnectionsWithResponse(ctx, c.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	if active.StatusCode() != http.StatusOK || active.JSON200 == nil {
+		return nil, &apierror.APIError{
+			Err: fmt.Errorf("could not list active connections: %s", active.Status()),
+		}
+	}
+
+	connections, err := c.ListConnectionsWithResponse(ctx, c.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	if connections.StatusCode() != http.StatusOK || connections.JSON200 == nil {
+		return nil, &apierror.APIError{
+			Err: fmt.Errorf("could not list connections: %s", connections.Status()),
+		}
+	}
+
+	groups, err := c.ListConnectionGroupsWithResponse(ctx, c.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	if groups.StatusCode() != http.StatusOK || groups.JSON200 == nil {
+		return nil, &apierror.APIError{
+			Err: fmt.Errorf("could not list connection groups: %s", groups.Status()),
+		}
+	}
+
+	return activeConnectionStats(*active.JSON200, *connections.JSON200, *groups.JSON200), nil
+}
+
+// activeConnectionStats counts active connections per connection and
+// the distinct users of active connections.
+func activeConnectionStats(active gen.ActiveConnections, connections gen.Connections, groups gen.ConnectionGroups) *ActiveConnectionStats {
+	counts := map[string]int{}
+	users := map[string]struct{}{}
+
+	for _, a := range active {
+		if a.ConnectionIdentifier != nil {
+			counts[*a.ConnectionIdentifier]++
+		}
+
+		if a.Username != nil {
+			users[*a.Username] = struct{}{}
+		}
+	}
+
+	stats := &ActiveConnectionStats{Users: len(users)}
+
+	for id, connection := range connections {
+		stats.Connections = append(stats.Connections, ConnectionStats{
+			Identifier: id,
+			Name:       connection.Name,
+			Group:      groupPath(connection.ParentIdentifier, groups),
+			Active:     counts[id],
+		})
+	}
+
+	slices.SortFunc(stats.Connections, func(a, b ConnectionStats) int {
+		if c := strings.Compare(a.Group, b.Group); c != 0 {
+			return c
+		}
+
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Identifier, b.Identifier)
+	})
+
+	// Connections are sorted by group.
+	for _, connection := range stats.Connections {
+		if n := len(stats.Groups); n > 0 && stats.Groups[n-1].Group == connection.Group {
+			stats.Groups[n-1].Active += connection.Active
+			continue
+		}
+
+		stats.Groups = append(stats.Groups, GroupStats{Group: connection.Group, Active: connection.Active})
+	}
+
+	return stats
+}
+
+// groupPath returns the path of a connection group by following
+// its parents up to the root group.
+func groupPath(identifier string, groups gen.ConnectionGroups) string {
+	var names []string
+
+	// Bounded by the number of groups in case of cycles.
+	for range len(groups) {
+		group, ok := groups[identifier]
+		if !ok {
+			break
+		}
+
+		names = append([]string{group.Name}, names...)
+		identifier = group.ParentIdentifier
+	}
+
+	return "/" + strings.Join(names, "/")
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+
+	"github.com/guacamole-operator/guacamole-operator/internal/client/gen"
+)
+
+func TestActiveConnectionStats(t *testing.T) {
+	active := gen.ActiveConnections{
+		"a": {ConnectionIdentifier: ptr.To("1"), Username: ptr.To("alice")},
+		"b": {ConnectionIdentifier: ptr.To("1"), Username: ptr.To("bob")},
+		"c": {ConnectionIdentifier: ptr.To("2"), Username: ptr.To("alice")},
+	}
+
+	connections := gen.Connections{
+		"1": {Name: "jumphost", ParentIdentifier: "ROOT"},
+		"2": {Name: "db", ParentIdentifier: "10"},
+		"3": {Name: "web", ParentIdentifier: "10"},
+		"4": {Name: "db", ParentIdentifier: "11"},
+	}
+
+	groups := gen.ConnectionGroups{
+		"10": {Identifier: ptr.To("10"), Name: "servers", ParentIdentifier: "ROOT"},
+		"11": {Identifier: ptr.To("11"), Name: "servers", ParentIdentifier: "10"},
+	}
+
+	got := activeConnectionStats(active, connections, groups)
+	want := &ActiveConnectionStats{
+		Connections: []ConnectionStats{
+			{Identifier: "1", Name: "jumphost", Group: "/", Active: 2},
+			{Identifier: "2", Name: "db", Group: "/servers", Active: 1},
+			{Identifier: "3", Name: "web", Group: "/servers", Active: 0},
+			// Same names of connection and group, distinct path.
+			{Identifier: "4", Name: "db", Group: "/servers/servers", Active: 0},
+		},
+		Groups: []GroupStats{
+			{Group: "/", Active: 2},
+			{Group: "/servers", Active: 1},
+			{Group: "/servers/servers", Active: 0},
+		},
+		Users: 2,
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,95 @@
+// Package credentials resolves connection parameters from Secrets
+// and external credential providers.
+package credentials
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Secret is a resolved secret value.
+type Secret struct {
+	// Value of the secret.
+	Value string
+	// Duration the value is valid. Zero if unknown.
+	TTL time.Duration
+}
+
+// Provider resolves secret values from an external secret store.
+type Provider interface {
+	// Get returns the value of a key of the secret at path.
+	Get(ctx context.Context, path, key string) (*Secret, error)
+}
+
+// Cache caches the secret values of a provider. Values are cached for the
+// TTL reported by the provider or the default TTL if none is reported.
+// Expired values are removed on the next miss.
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+
+	// Clock of the cache, defaults to time.Now.
+	Now func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   string
+	expires time.Time
+}
+
+var _ Provider = &Cache{}
+
+// NewCache returns a cache for a provider.
+func NewCache(provider Provider, ttl time.Duration) *Cache {
+	return &Cache{
+		provider: provider,
+		ttl:      ttl,
+		entries:  map[string]cacheEntry{},
+	}
+}
+
+// Get implements Provider. The TTL of returned secrets is the
+// remaining duration the value is cached.
+func (c *Cache) Get(ctx context.Context, path, key string) (*Secret, error) {
+	now := time.Now
+	if c.Now != nil {
+		now = c.Now
+	}
+
+	id := path + "#" + key
+
+	c.mutex.Lock()
+	entry, ok := c.entries[id]
+	c.mutex.Unlock()
+
+	if ok && now().Before(entry.expires) {
+		return &Secret{Value: entry.value, TTL: entry.expires.Sub(now())}, nil
+	}
+
+	secret, err := c.provider.Get(ctx, path, key)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := c.ttl
+	if secret.TTL > 0 {
+		ttl = secret.TTL
+	}
+
+	c.mutex.Lock()
+	// Remove expired entries, e.g. of secrets no longer referenced.
+	for k, e := range c.entries {
+		if !now().Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[id] = cacheEntry{value: secret.Value, expires: now().Add(ttl)}
+	c.mutex.Unlock()
+
+	return &Secret{Value: secret.Value, TTL: ttl}, nil
+}
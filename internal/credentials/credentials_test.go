@@ -0,0 +1,296 @@
+package credentials_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+	"github.com/guacamole-operator/guacamole-operator/internal/credentials"
+)
+
+// fakeVault is a minimal stand-in for a Vault dev server with the
+// Kubernetes auth method and a KV version 2 secrets engine.
+type fakeVault struct {
+	*httptest.Server
+	password atomic.Value
+	token    atomic.Value
+	logins   atomic.Int32
+	reads    atomic.Int32
+}
+
+func newFakeVault(t *testing.T) *fakeVault {
+	t.Helper()
+
+	v := &fakeVault{}
+	v.password.Store("initial")
+	v.token.Store("")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/auth/kubernetes/login", func(w http.ResponseWriter, r *http.Request) {
+		var login map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&login); err != nil || login["role"] != "guacamole" || login["jwt"] != "sa-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		token := "token-" + time.Now().String()
+		v.token.Store(token)
+		v.logins.Add(1)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"auth": map[string]any{"client_token": token, "lease_duration": 3600},
+		})
+	})
+	secret := func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != v.token.Load().(string) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		v.reads.Add(1)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"lease_duration": 0,
+			"data": map[string]any{
+				"data": map[string]any{"password": v.password.Load().(string)},
+			},
+		})
+	}
+	mux.HandleFunc("GET /v1/secret/data/rdp/service", secret)
+	// Secrets scoped by the namespace of connections.
+	mux.HandleFunc("GET /v1/secret/data/default/rdp/service", secret)
+	mux.HandleFunc("GET /v1/secret/data/guacamole/team-a/rdp/service", secret)
+
+	v.Server = httptest.NewServer(mux)
+	t.Cleanup(v.Close)
+
+	return v
+}
+
+func newVaultProvider(t *testing.T, address string) *credentials.Vault {
+	t.Helper()
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenPath, []byte("sa-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return &credentials.Vault{
+		Address:   address,
+		Mount:     "secret",
+		AuthMount: "kubernetes",
+		Role:      "guacamole",
+		TokenPath: tokenPath,
+	}
+}
+
+func TestVault(t *testing.T) {
+	server := newFakeVault(t)
+	vault := newVaultProvider(t, server.URL)
+	ctx := context.Background()
+
+	secret, err := vault.Get(ctx, "rdp/service", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret.Value != "initial" {
+		t.Errorf("expected value %q, got %q", "initial", secret.Value)
+	}
+
+	// Token is reused.
+	if _, err := vault.Get(ctx, "rdp/service", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := server.logins.Load(); got != 1 {
+		t.Errorf("expected 1 login, got %d", got)
+	}
+
+	// Revoked token triggers a new login.
+	server.token.Store("revoked")
+
+	if _, err := vault.Get(ctx, "rdp/service", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := server.logins.Load(); got != 2 {
+		t.Errorf("expected 2 logins, got %d", got)
+	}
+
+	if _, err := vault.Get(ctx, "rdp/service", "missing"); err == nil {
+		t.Error("expected error for missing key")
+	}
+
+	// Paths must not escape the mount of the secrets engine.
+	for _, path := range []string{"../../sys/mounts", "rdp/../../../sys/mounts", "/rdp/service"} {
+		if _, err := vault.Get(ctx, path, "password"); err == nil {
+			t.Errorf("expected error for path %s", path)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	server := newFakeVault(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cache := credentials.NewCache(newVaultProvider(t, server.URL), time.Hour)
+	cache.Now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	if _, err := cache.Get(ctx, "rdp/service", "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotated password is returned once the cached value expires.
+	server.password.Store("rotated")
+	now = now.Add(30 * time.Minute)
+
+	secret, err := cache.Get(ctx, "rdp/service", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret.Value != "initial" || secret.TTL != 30*time.Minute {
+		t.Errorf("expected cached value with TTL 30m, got %q with TTL %s", secret.Value, secret.TTL)
+	}
+
+	now = now.Add(time.Hour)
+
+	secret, err = cache.Get(ctx, "rdp/service", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret.Value != "rotated" {
+		t.Errorf("expected value %q, got %q", "rotated", secret.Value)
+	}
+
+	if got := server.reads.Load(); got != 2 {
+		t.Errorf("expected 2 reads, got %d", got)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	server := newFakeVault(t)
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "rdp", Namespace: "default"},
+		Data:       map[string][]byte{"username": []byte("service")},
+	}).Build()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var providers int
+	resolver := &credentials.Resolver{
+		Client: c,
+		NewVault: func(config *v1alpha1.Vault, _ []byte) (credentials.Provider, error) {
+			providers++
+			vault := newVaultProvider(t, config.Address)
+			return credentials.NewCache(vault, config.TTL.Duration), nil
+		},
+		Now: func() time.Time { return now },
+	}
+
+	guac := &v1alpha1.Guacamole{
+		ObjectMeta: metav1.ObjectMeta{Name: "guac", Namespace: "default"},
+		Spec: v1alpha1.GuacamoleSpec{
+			Credentials: &v1alpha1.Credentials{
+				Vault: &v1alpha1.Vault{
+					Address: server.URL,
+					TTL:     &metav1.Duration{Duration: 10 * time.Minute},
+				},
+			},
+		},
+	}
+
+	connection := &v1alpha1.Connection{
+		ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "default"},
+		Spec: v1alpha1.ConnectionSpec{
+			ParametersFrom: []v1alpha1.ParameterSource{
+				{
+					Name: "username",
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "rdp"},
+						Key:                  "username",
+					},
+				},
+				{
+					Name:  "password",
+					Vault: &v1alpha1.VaultSecretKeySelector{Path: "rdp/service", Key: "password"},
+				},
+			},
+		},
+	}
+
+	// Paths default to the namespace of the connection.
+	params, ttl, err := resolver.Resolve(context.Background(), guac, connection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"username": "service", "password": "initial"}
+	if !cmp.Equal(want, params) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, params))
+	}
+
+	if ttl != 10*time.Minute {
+		t.Errorf("expected TTL 10m, got %s", ttl)
+	}
+
+	// Paths are scoped by the prefix and the namespace of the connection.
+	guac.Spec.Credentials.Vault.PathPrefix = "guacamole/$(NAMESPACE)"
+	connection.Namespace = "team-a"
+	connection.Spec.ParametersFrom = connection.Spec.ParametersFrom[1:]
+
+	params, _, err = resolver.Resolve(context.Background(), guac, connection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if params["password"] != "initial" {
+		t.Errorf("expected password %q, got %q", "initial", params["password"])
+	}
+
+	connection.Spec.ParametersFrom[0].Vault.Path = "../team-b/rdp/service"
+
+	if _, _, err := resolver.Resolve(context.Background(), guac, connection); err == nil {
+		t.Error("expected error for path outside of the prefix")
+	}
+
+	// Providers of outdated configurations are removed once unused.
+	connection.Spec.ParametersFrom[0].Vault.Path = "rdp/service"
+	guac.Spec.Credentials.Vault.TTL = &metav1.Duration{Duration: 20 * time.Minute}
+	now = now.Add(2 * time.Hour)
+
+	if _, _, err := resolver.Resolve(context.Background(), guac, connection); err != nil {
+		t.Fatal(err)
+	}
+
+	guac.Spec.Credentials.Vault.TTL = &metav1.Duration{Duration: 10 * time.Minute}
+
+	if _, _, err := resolver.Resolve(context.Background(), guac, connection); err != nil {
+		t.Fatal(err)
+	}
+
+	if providers != 3 {
+		t.Errorf("expected 3 providers, got %d", providers)
+	}
+}
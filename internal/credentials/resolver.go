@@ -0,0 +1,237 @@
+package credentials
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+const (
+	// Default duration resolved secrets are cached.
+	defaultTTL = 5 * time.Minute
+	// Default path prefix of Vault secrets, scoping them per namespace.
+	defaultPathPrefix = "$(NAMESPACE)"
+	// Duration after which unused providers are removed, e.g. of
+	// deleted instances or outdated configurations.
+	providerIdleTimeout = time.Hour
+)
+
+// Resolver resolves the parameter sources of connections. Providers are
+// kept per configuration, so that caches and tokens are shared between
+// reconciles.
+type Resolver struct {
+	// Client to read Secrets.
+	Client client.Reader
+
+	// Creates Vault providers, defaults to providers with caches.
+	NewVault func(config *v1alpha1.Vault, caCert []byte) (Provider, error)
+
+	// Clock of the resolver, defaults to time.Now.
+	Now func() time.Time
+
+	mutex     sync.Mutex
+	providers map[string]*providerEntry
+}
+
+type providerEntry struct {
+	provider Provider
+	used     time.Time
+}
+
+// Resolve resolves the parameter sources of a connection. Returns the
+// resolved parameters and the duration until the first resolved value
+// expires, which is zero if no value expires.
+func (r *Resolver) Resolve(ctx context.Context, guac *v1alpha1.Guacamole, connection *v1alpha1.Connection) (map[string]string, time.Duration, error) {
+	params := make(map[string]string, len(connection.Spec.ParametersFrom))
+
+	var ttl time.Duration
+
+	for _, source := range connection.Spec.ParametersFrom {
+		switch {
+		case source.SecretKeyRef != nil:
+			var secret corev1.Secret
+			key := types.NamespacedName{Name: source.SecretKeyRef.Name, Namespace: connection.Namespace}
+			if err := r.Client.Get(ctx, key, &secret); err != nil {
+				return nil, 0, fmt.Errorf("error getting secret for parameter %s: %w", source.Name, err)
+			}
+
+			value, ok := secret.Data[source.SecretKeyRef.Key]
+			if !ok {
+				return nil, 0, fmt.Errorf("key %s not found in secret %s", source.SecretKeyRef.Key, key.Name)
+			}
+
+			params[source.Name] = string(value)
+		case source.Vault != nil:
+			if guac.Spec.Credentials == nil || guac.Spec.Credentials.Vault == nil {
+				return nil, 0, fmt.Errorf("vault not configured in instance %s", guac.Name)
+			}
+
+			provider, err := r.vault(ctx, guac)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			path, err := vaultPath(guac.Spec.Credentials.Vault, connection.Namespace, source.Vault.Path)
+			if err != nil {
+				return nil, 0, fmt.Errorf("error resolving parameter %s: %w", source.Name, err)
+			}
+
+			secret, err := provider.Get(ctx, path, source.Vault.Key)
+			if err != nil {
+				return nil, 0, fmt.Errorf("error resolving parameter %s: %w", source.Name, err)
+			}
+
+			params[source.Name] = secret.Value
+
+			if secret.TTL > 0 && (ttl == 0 || secret.TTL < ttl) {
+				ttl = secret.TTL
+			}
+		default:
+			return nil, 0, fmt.Errorf("no source for parameter %s", source.Name)
+		}
+	}
+
+	return params, ttl, nil
+}
+
+// vaultPath returns the path of a secret below the path prefix of the
+// configuration.
+func vaultPath(config *v1alpha1.Vault, namespace, path string) (string, error) {
+	if err := validatePath(path); err != nil {
+		return "", err
+	}
+
+	prefix := config.PathPrefix
+	if prefix == "" {
+		prefix = defaultPathPrefix
+	}
+
+	prefix = strings.ReplaceAll(prefix, "$(NAMESPACE)", namespace)
+	if err := validatePath(prefix); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(prefix, "/") + "/" + path, nil
+}
+
+// vault returns the Vault provider of an instance.
+func (r *Resolver) vault(ctx context.Context, guac *v1alpha1.Guacamole) (Provider, error) {
+	config := guac.Spec.Credentials.Vault
+
+	var caCert []byte
+	if ref := config.CACertSecretRef; ref != nil {
+		var secret corev1.Secret
+		if err := r.Client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: guac.Namespace}, &secret); err != nil {
+			return nil, fmt.Errorf("error getting Vault CA certificate: %w", err)
+		}
+
+		caCert = secret.Data[ref.Key]
+	}
+
+	ttl := ""
+	if config.TTL != nil {
+		ttl = config.TTL.Duration.String()
+	}
+
+	// Providers are identified by their configuration.
+	sum := sha256.Sum256(caCert)
+	id := strings.Join([]string{
+		config.Address, config.Mount, config.Auth.Mount, config.Auth.Role,
+		ttl, hex.EncodeToString(sum[:]),
+	}, "|")
+
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, entry := range r.providers {
+		if key != id && now().Sub(entry.used) > providerIdleTimeout {
+			delete(r.providers, key)
+		}
+	}
+
+	if entry, ok := r.providers[id]; ok {
+		entry.used = now()
+		return entry.provider, nil
+	}
+
+	newVault := r.NewVault
+	if newVault == nil {
+		newVault = NewVault
+	}
+
+	provider, err := newVault(config, caCert)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.providers == nil {
+		r.providers = map[string]*providerEntry{}
+	}
+
+	r.providers[id] = &providerEntry{provider: provider, used: now()}
+
+	return provider, nil
+}
+
+// NewVault returns a cached Vault provider for a configuration.
+func NewVault(config *v1alpha1.Vault, caCert []byte) (Provider, error) {
+	httpClient := &http.Client{}
+
+	if len(caCert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("invalid Vault CA certificate")
+		}
+
+		httpClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    pool,
+				MinVersion: tls.VersionTLS12,
+			},
+		}
+	}
+
+	mount := config.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+
+	authMount := config.Auth.Mount
+	if authMount == "" {
+		authMount = "kubernetes"
+	}
+
+	ttl := defaultTTL
+	if config.TTL != nil {
+		ttl = config.TTL.Duration
+	}
+
+	vault := &Vault{
+		Address:    config.Address,
+		Mount:      mount,
+		AuthMount:  authMount,
+		Role:       config.Auth.Role,
+		HTTPClient: httpClient,
+	}
+
+	return NewCache(vault, ttl), nil
+}
@@ -0,0 +1,221 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Token of the operator's service account used for the Kubernetes auth method.
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	// Tokens are renewed by a new login shortly before they expire.
+	tokenExpiryMargin = 30 * time.Second
+)
+
+var errPermissionDenied = errors.New("permission denied")
+
+// Vault reads secrets from a KV version 2 secrets engine and
+// authenticates via the Kubernetes auth method.
+type Vault struct {
+	// Address of Vault.
+	Address string
+	// Mount path of the KV secrets engine.
+	Mount string
+	// Mount path of the Kubernetes auth method.
+	AuthMount string
+	// Role of the Kubernetes auth method.
+	Role string
+	// Path of the service account token. Defaults to the
+	// token of the pod's service account.
+	TokenPath string
+	// HTTP client, defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	mutex  sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+var _ Provider = &Vault{}
+
+type vaultLoginResponse struct {
+	Auth struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int64  `json:"lease_duration"`
+	} `json:"auth"`
+}
+
+type vaultSecretResponse struct {
+	LeaseDuration int64 `json:"lease_duration"`
+	Data          struct {
+		Data map[string]any `json:"data"`
+	} `json:"data"`
+}
+
+// Get implements Provider.
+func (v *Vault) Get(ctx context.Context, path, key string) (*Secret, error) {
+	secret, err := v.read(ctx, path)
+	if errors.Is(err, errPermissionDenied) {
+		// Token might have been revoked, login again.
+		v.mutex.Lock()
+		v.token = ""
+		v.mutex.Unlock()
+
+		secret, err = v.read(ctx, path)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := secret.Data.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in Vault secret %s", key, path)
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		s = string(b)
+	}
+
+	return &Secret{
+		Value: s,
+		TTL:   time.Duration(secret.LeaseDuration) * time.Second,
+	}, nil
+}
+
+// read reads a secret from the KV secrets engine.
+func (v *Vault) read(ctx context.Context, path string) (*vaultSecretResponse, error) {
+	// The path is joined and cleaned, which must not escape the mount.
+	if err := validatePath(path); err != nil {
+		return nil, err
+	}
+
+	token, err := v.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := url.JoinPath(v.Address, "v1", v.Mount, "data", path)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", token)
+
+	var secret vaultSecretResponse
+	if err := v.do(req, &secret); err != nil {
+		return nil, fmt.Errorf("error reading Vault secret %s: %w", path, err)
+	}
+
+	return &secret, nil
+}
+
+// validatePath returns an error if a secret path is absolute or
+// contains `..` segments.
+func validatePath(path string) error {
+	if strings.HasPrefix(path, "/") || slices.Contains(strings.Split(path, "/"), "..") {
+		return fmt.Errorf("invalid Vault secret path %s: must be relative and must not contain '..'", path)
+	}
+
+	return nil
+}
+
+// login returns a valid token. Logs in with the service account token
+// if no token exists or the token expires.
+func (v *Vault) login(ctx context.Context) (string, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.token != "" && (v.expiry.IsZero() || time.Now().Before(v.expiry.Add(-tokenExpiryMargin))) {
+		return v.token, nil
+	}
+
+	tokenPath := v.TokenPath
+	if tokenPath == "" {
+		tokenPath = serviceAccountTokenPath
+	}
+
+	jwt, err := os.ReadFile(tokenPath)
+	if err != nil {
+		return "", fmt.Errorf("error reading service account token: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"role": v.Role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	endpoint, err := url.JoinPath(v.Address, "v1", "auth", v.AuthMount, "login")
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	var login vaultLoginResponse
+	if err := v.do(req, &login); err != nil {
+		return "", fmt.Errorf("error logging in to Vault: %w", err)
+	}
+
+	v.token = login.Auth.ClientToken
+	v.expiry = time.Time{}
+
+	if login.Auth.LeaseDuration > 0 {
+		v.expiry = time.Now().Add(time.Duration(login.Auth.LeaseDuration) * time.Second)
+	}
+
+	return v.token, nil
+}
+
+// do executes a request and decodes the response.
+func (v *Vault) do(req *http.Request, out any) error {
+	httpClient := v.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		return errPermissionDenied
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,159 @@
+// Package jsonauth implements payloads of the Guacamole
+// encrypted JSON authentication extension.
+//
+// A payload is signed with HMAC-SHA256, the signature is prepended to the
+// JSON document and the result is encrypted with AES-128-CBC using an IV
+// of zeros, as expected by the `guacamole-auth-json` extension.
+package jsonauth
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of secret keys in bytes.
+const KeySize = 16
+
+// Payload defines the authentication payload.
+type Payload struct {
+	// Name of the user.
+	Username string `json:"username"`
+	// Expiry as milliseconds since the epoch.
+	Expires int64 `json:"expires,omitempty"`
+	// Connections available to the user by name.
+	Connections map[string]Connection `json:"connections"`
+}
+
+// Connection defines a connection of a payload.
+type Connection struct {
+	// Identifier to share the connection between users.
+	ID string `json:"id,omitempty"`
+	// Protocol of the connection.
+	Protocol string `json:"protocol"`
+	// Parameters of the connection.
+	Parameters map[string]string `json:"parameters"`
+}
+
+// GenerateKey returns a random secret key in hex encoding.
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(key), nil
+}
+
+// ParseKey parses a secret key in hex encoding.
+func ParseKey(s string) ([]byte, error) {
+	key, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding key: %w", err)
+	}
+
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key size %d, expected %d bytes", len(key), KeySize)
+	}
+
+	return key, nil
+}
+
+// Encode signs and encrypts a payload.
+func Encode(key []byte, payload *Payload) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	plaintext := pad(append(mac.Sum(nil), data...), block.BlockSize())
+	ciphertext := make([]byte, len(plaintext))
+
+	iv := make([]byte, block.BlockSize())
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// Decode decrypts a payload and verifies its signature.
+func Decode(key []byte, s string) (*Payload, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) == 0 || len(ciphertext)%block.BlockSize() != 0 {
+		return nil, errors.New("invalid payload size")
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+
+	iv := make([]byte, block.BlockSize())
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	plaintext, err = unpad(plaintext, block.BlockSize())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(plaintext) < sha256.Size {
+		return nil, errors.New("invalid payload size")
+	}
+
+	signature, data := plaintext[:sha256.Size], plaintext[sha256.Size:]
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("invalid signature")
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+
+	return &payload, nil
+}
+
+// pad adds PKCS#7 padding.
+func pad(b []byte, size int) []byte {
+	n := size - len(b)%size
+	return append(b, bytes.Repeat([]byte{byte(n)}, n)...)
+}
+
+// unpad removes PKCS#7 padding.
+func unpad(b []byte, size int) ([]byte, error) {
+	n := int(b[len(b)-1])
+	if n == 0 || n > size || n > len(b) {
+		return nil, errors.New("invalid padding")
+	}
+
+	if !bytes.Equal(b[len(b)-n:], bytes.Repeat([]byte{byte(n)}, n)) {
+		return nil, errors.New("invalid padding")
+	}
+
+	return b[:len(b)-n], nil
+}
@@ -0,0 +1,214 @@
+package jsonauth_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+	"github.com/guacamole-operator/guacamole-operator/internal/jsonauth"
+)
+
+const testKey = "4c0b569e4c96df157eee1b65dd0e4d41"
+
+func TestEncodeDecode(t *testing.T) {
+	key, err := jsonauth.ParseKey(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &jsonauth.Payload{
+		Username: "alice",
+		Expires:  1700000000000,
+		Connections: map[string]jsonauth.Connection{
+			"server": {
+				Protocol:   "rdp",
+				Parameters: map[string]string{"hostname": "10.0.0.1", "port": "3389", "password": "secret"},
+			},
+		},
+	}
+
+	data, err := jsonauth.Encode(key, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := jsonauth.Decode(key, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+
+	// Payloads encrypted with another key are rejected.
+	other, _ := jsonauth.ParseKey("00000000000000000000000000000000")
+	if _, err := jsonauth.Decode(other, data); err == nil {
+		t.Error("expected error decoding payload with wrong key")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	for _, key := range []string{"", "abc", "4c0b569e4c96df157eee1b65dd0e4d", "zz0b569e4c96df157eee1b65dd0e4d41"} {
+		if _, err := jsonauth.ParseKey(key); err == nil {
+			t.Errorf("expected error parsing key %q", key)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = v1alpha1.AddToScheme(scheme)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1alpha1.Guacamole{
+			ObjectMeta: metav1.ObjectMeta{Name: "guac", Namespace: "default"},
+			Spec: v1alpha1.GuacamoleSpec{
+				Auth: v1alpha1.Auth{JSON: &v1alpha1.JSON{}},
+			},
+		},
+		&v1alpha1.Connection{
+			ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "default"},
+			Spec: v1alpha1.ConnectionSpec{
+				GuacamoleRef: v1alpha1.GuacamoleRef{Name: "guac"},
+				Protocol:     "rdp",
+				Parameters: &v1alpha1.ConnectionParameters{
+					RawMessage: []byte(`{"hostname":"10.0.0.1","port":"3389"}`),
+				},
+				ParametersFrom: []v1alpha1.ParameterSource{{
+					Name: "password",
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "rdp"},
+						Key:                  "password",
+					},
+				}},
+			},
+		},
+		&v1alpha1.Connection{
+			ObjectMeta: metav1.ObjectMeta{Name: "pooled", Namespace: "default"},
+			Spec: v1alpha1.ConnectionSpec{
+				GuacamoleRef: v1alpha1.GuacamoleRef{Name: "guac"},
+				Protocol:     "rdp",
+				GuacdPool:    ptr.To("office"),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rdp", Namespace: "default"},
+			Data:       map[string][]byte{"password": []byte("secret")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "guacamole-json-auth-guac", Namespace: "default"},
+			Data:       map[string][]byte{v1alpha1.JSONAuthSecretKey: []byte(testKey)},
+		},
+	).WithInterceptorFuncs(interceptor.Funcs{
+		// Token "token" authenticates a ServiceAccount allowed to create
+		// tokens for all connections except "restricted".
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			switch review := obj.(type) {
+			case *authenticationv1.TokenReview:
+				if review.Spec.Token == "token" {
+					review.Status.Authenticated = true
+					review.Status.User.Username = "system:serviceaccount:default:portal"
+				}
+			case *authorizationv1.SubjectAccessReview:
+				attributes := review.Spec.ResourceAttributes
+				review.Status.Allowed = review.Spec.User == "system:serviceaccount:default:portal" &&
+					attributes.Verb == "create" && attributes.Resource == "connections" && attributes.Subresource == "token" &&
+					attributes.Namespace == "default" && attributes.Name != "restricted"
+			default:
+				return c.Create(ctx, obj, opts...)
+			}
+
+			return nil
+		},
+	}).Build()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	server := &jsonauth.Server{
+		Client: c,
+		Now:    func() time.Time { return now },
+	}
+
+	request := func(token, connection string) *httptest.ResponseRecorder {
+		body := bytes.NewBufferString(`{"username":"alice","expiresIn":"15m"}`)
+		req := httptest.NewRequest(http.MethodPost, "/namespaces/default/connections/"+connection+"/token", body)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	if rec := request("wrong", "server"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	if rec := request("token", "restricted"); rec.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, rec.Code)
+	}
+
+	if rec := request("token", "missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+
+	if rec := request("token", "pooled"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, rec.Code)
+	}
+
+	rec := request("token", "server")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response jsonauth.TokenResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	key, _ := jsonauth.ParseKey(testKey)
+	got, err := jsonauth.Decode(key, response.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &jsonauth.Payload{
+		Username: "alice",
+		Expires:  now.Add(15 * time.Minute).UnixMilli(),
+		Connections: map[string]jsonauth.Connection{
+			"server": {
+				Protocol:   "rdp",
+				Parameters: map[string]string{"hostname": "10.0.0.1", "port": "3389", "password": "secret"},
+			},
+		},
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestServerRequiresTLS(t *testing.T) {
+	server := &jsonauth.Server{Addr: ":0"}
+
+	if err := server.Start(context.Background()); err == nil {
+		t.Error("expected error serving non-loopback address without certificate")
+	}
+}
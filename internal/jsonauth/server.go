@@ -0,0 +1,389 @@
+package jsonauth
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+	"github.com/guacamole-operator/guacamole-operator/internal/client/gen"
+	"github.com/guacamole-operator/guacamole-operator/internal/credentials"
+	reconciler "github.com/guacamole-operator/guacamole-operator/internal/reconciler/connection"
+)
+
+const (
+	// Default validity of payloads.
+	defaultExpiry = 5 * time.Minute
+	// Maximum validity of payloads.
+	maxExpiry = 24 * time.Hour
+
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 10 * time.Second
+)
+
+// errGuacdPoolUnsupported indicates a connection routed to a guacd pool.
+// Connections of payloads always use the guacd of the instance.
+var errGuacdPoolUnsupported = errors.New("guacd pools are not supported by JSON auth")
+
+// Subresource of connections clients need permission to create.
+const tokenSubresource = "token"
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// Server mints payloads for connections. Clients authenticate with a
+// Kubernetes bearer token, e.g. of a ServiceAccount, and require
+// permission to create the subresource `connections/token` of the
+// requested connection.
+type Server struct {
+	// Address to listen on.
+	Addr string
+	// Directory containing the serving certificate `tls.crt` and key
+	// `tls.key`, reloaded on change. Without, the server only listens
+	// on loopback addresses.
+	CertDir string
+	// Client to read connections and keys and to review tokens.
+	Client client.Client
+	// Resolver of parameter sources of connections. Share it with the
+	// Connection reconciler to reuse cached secrets. Defaults to a
+	// resolver reading Secrets with Client.
+	Credentials *credentials.Resolver
+	// Clock of the server, defaults to time.Now.
+	Now func() time.Time
+}
+
+// TokenRequest defines the request for a payload.
+type TokenRequest struct {
+	// Name of the user.
+	Username string `json:"username"`
+	// Validity of the payload, e.g. `15m`. Defaults to 5 minutes.
+	ExpiresIn string `json:"expiresIn,omitempty"`
+}
+
+// TokenResponse defines the response containing a payload.
+type TokenResponse struct {
+	// Signed and encrypted payload, passed to Guacamole as `data` parameter.
+	Data string `json:"data"`
+	// Expiry of the payload.
+	Expires time.Time `json:"expires"`
+}
+
+// Start implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	if s.CertDir == "" {
+		if !loopback(s.Addr) {
+			return fmt.Errorf("JSON auth endpoint on %s requires a certificate, only loopback addresses are served without TLS", s.Addr)
+		}
+	} else {
+		watcher, err := certwatcher.New(filepath.Join(s.CertDir, "tls.crt"), filepath.Join(s.CertDir, "tls.key"))
+		if err != nil {
+			return fmt.Errorf("error loading JSON auth certificate: %w", err)
+		}
+
+		go func() {
+			if err := watcher.Start(ctx); err != nil {
+				log.FromContext(ctx).Error(err, "Failed to watch JSON auth certificate.")
+			}
+		}()
+
+		srv.TLSConfig = &tls.Config{
+			GetCertificate: watcher.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		}
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errCh <- srv.ListenAndServeTLS("", "")
+			return
+		}
+
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+// Payloads are minted by all replicas.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the HTTP handler of the server.
+func (s *Server) Handler() http.Handler {
+	if s.Credentials == nil {
+		s.Credentials = &credentials.Resolver{Client: s.Client}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /namespaces/{namespace}/connections/{name}/token", s.authorize(s.handleToken))
+
+	return mux
+}
+
+// authorize authenticates the bearer token of a request via TokenReview and
+// checks via SubjectAccessReview that its user may create a token for the
+// requested connection.
+func (s *Server) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := log.FromContext(r.Context())
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		tokenReview := &authenticationv1.TokenReview{
+			Spec: authenticationv1.TokenReviewSpec{Token: token},
+		}
+		if err := s.Client.Create(r.Context(), tokenReview); err != nil {
+			logger.Error(err, "Failed to review JSON auth bearer token.")
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		if !tokenReview.Status.Authenticated {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		user := tokenReview.Status.User
+		extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for k, v := range user.Extra {
+			extra[k] = authorizationv1.ExtraValue(v)
+		}
+
+		accessReview := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   user.Username,
+				UID:    user.UID,
+				Groups: user.Groups,
+				Extra:  extra,
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   r.PathValue("namespace"),
+					Verb:        "create",
+					Group:       v1alpha1.GroupVersion.Group,
+					Resource:    "connections",
+					Subresource: tokenSubresource,
+					Name:        r.PathValue("name"),
+				},
+			},
+		}
+		if err := s.Client.Create(r.Context(), accessReview); err != nil {
+			logger.Error(err, "Failed to review access to JSON auth endpoint.")
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		if !accessReview.Status.Allowed {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// loopback returns whether an address only listens on loopback interfaces.
+func loopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	logger := log.FromContext(r.Context())
+
+	var request TokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if request.Username == "" {
+		http.Error(w, "username missing", http.StatusBadRequest)
+		return
+	}
+
+	expiry := defaultExpiry
+	if request.ExpiresIn != "" {
+		d, err := time.ParseDuration(request.ExpiresIn)
+		if err != nil || d <= 0 || d > maxExpiry {
+			http.Error(w, "invalid expiry", http.StatusBadRequest)
+			return
+		}
+
+		expiry = d
+	}
+
+	key := types.NamespacedName{
+		Namespace: r.PathValue("namespace"),
+		Name:      r.PathValue("name"),
+	}
+
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	expires := now().Add(expiry)
+
+	data, err := s.mint(r.Context(), key, request.Username, expires)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			http.Error(w, "connection not found", http.StatusNotFound)
+			return
+		}
+
+		if errors.Is(err, errGuacdPoolUnsupported) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		logger.Error(err, "Failed to mint JSON auth payload.", "connection", key)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(TokenResponse{
+		Data:    data,
+		Expires: expires,
+	})
+}
+
+// mint returns a payload granting a user access to a connection.
+func (s *Server) mint(ctx context.Context, key types.NamespacedName, username string, expires time.Time) (string, error) {
+	var connection v1alpha1.Connection
+	if err := s.Client.Get(ctx, key, &connection); err != nil {
+		return "", err
+	}
+
+	var guac v1alpha1.Guacamole
+	if err := s.Client.Get(ctx, types.NamespacedName{Namespace: key.Namespace, Name: connection.Spec.GuacamoleRef.Name}, &guac); err != nil {
+		return "", err
+	}
+
+	if guac.Spec.Auth.JSON == nil {
+		return "", fmt.Errorf("JSON auth not configured in instance %s", guac.Name)
+	}
+
+	var secret corev1.Secret
+	if err := s.Client.Get(ctx, types.NamespacedName{Namespace: key.Namespace, Name: guac.Spec.Auth.JSON.SecretName(guac.Name)}, &secret); err != nil {
+		return "", err
+	}
+
+	secretKey, err := ParseKey(string(secret.Data[v1alpha1.JSONAuthSecretKey]))
+	if err != nil {
+		return "", err
+	}
+
+	if connection.Spec.GuacdPool != nil {
+		return "", errGuacdPoolUnsupported
+	}
+
+	// Parameters are resolved like for connections synchronized via the API.
+	resolved, _, err := s.Credentials.Resolve(ctx, &guac, &connection)
+	if err != nil {
+		return "", err
+	}
+
+	connectionParams, err := reconciler.Parameters(&guac, &connection, resolved)
+	if err != nil {
+		return "", err
+	}
+
+	params, err := parameterValues(connectionParams)
+	if err != nil {
+		return "", err
+	}
+
+	payload := &Payload{
+		Username: username,
+		Expires:  expires.UnixMilli(),
+		Connections: map[string]Connection{
+			connection.Name: {
+				Protocol:   string(connection.Spec.Protocol),
+				Parameters: params,
+			},
+		},
+	}
+
+	return Encode(secretKey, payload)
+}
+
+// parameterValues converts connection parameters to the string values
+// expected in payloads.
+func parameterValues(connectionParams gen.ConnectionParameters) (map[string]string, error) {
+	data, err := connectionParams.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{}
+
+	for k, v := range raw {
+		switch value := v.(type) {
+		case nil:
+			continue
+		case string:
+			params[k] = value
+		default:
+			b, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+
+			params[k] = string(b)
+		}
+	}
+
+	return params, nil
+}
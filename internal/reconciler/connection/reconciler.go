@@ -2,8 +2,14 @@ package connection
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+
+	"k8s.io/utils/ptr"
 
 	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
 	"github.com/guacamole-operator/guacamole-operator/internal/client"
@@ -17,18 +23,26 @@ type Reconciler struct {
 
 	// Concurrency factor for Guacamole API calls.
 	concurrency int
+
+	// Guacamole instance the connections belong to.
+	guac *v1alpha1.Guacamole
 }
 
+// guacdPort is the port of guacd services.
+const guacdPort = 4822
+
 // New instantiates a reconciler.
-func New(client *client.Client, concurrency int) *Reconciler {
+func New(client *client.Client, concurrency int, guac *v1alpha1.Guacamole) *Reconciler {
 	return &Reconciler{
 		client:      client,
 		concurrency: concurrency,
+		guac:        guac,
 	}
 }
 
-// Sync synchronizes the connection resource.
-func (r *Reconciler) Sync(ctx context.Context, obj *v1alpha1.Connection) error {
+// Sync synchronizes the connection resource. Resolved parameters
+// override the parameters of the connection resource.
+func (r *Reconciler) Sync(ctx context.Context, obj *v1alpha1.Connection, resolved map[string]string) error {
 	// Normalize parameters.
 	if obj.Spec.Parameters == nil {
 		obj.Spec.Parameters = &v1alpha1.ConnectionParameters{
@@ -36,8 +50,12 @@ func (r *Reconciler) Sync(ctx context.Context, obj *v1alpha1.Connection) error {
 		}
 	}
 
-	params := gen.ConnectionParameters{}
-	err := params.UnmarshalJSON(obj.Spec.Parameters.RawMessage)
+	params, err := Parameters(r.guac, obj, resolved)
+	if err != nil {
+		return err
+	}
+
+	attributes, err := r.connectionAttributes(obj)
 	if err != nil {
 		return err
 	}
@@ -70,7 +88,7 @@ func (r *Reconciler) Sync(ctx context.Context, obj *v1alpha1.Connection) error {
 			Protocol:         obj.Spec.Protocol,
 			ParentIdentifier: parent,
 			Parameters:       params,
-			Attributes:       gen.ConnectionAttributes{},
+			Attributes:       attributes,
 		}
 
 		// Update connection. This can fail when a connection changes its parent
@@ -95,6 +113,7 @@ func (r *Reconciler) Sync(ctx context.Context, obj *v1alpha1.Connection) error {
 			Protocol:         obj.Spec.Protocol,
 			ParentIdentifier: parent,
 			Parameters:       params,
+			Attributes:       attributes,
 		}
 
 		response, err := r.client.CreateConnectionWithResponse(ctx, r.client.Source, request)
@@ -140,6 +159,152 @@ func (r *Reconciler) Sync(ctx context.Context, obj *v1alpha1.Connection) error {
 	return nil
 }
 
+// Parameters returns the parameters of a connection including resolved
+// parameters and the recording configuration.
+func Parameters(guac *v1alpha1.Guacamole, obj *v1alpha1.Connection, resolved map[string]string) (gen.ConnectionParameters, error) {
+	params := gen.ConnectionParameters{}
+
+	raw := []byte("{}")
+	if obj.Spec.Parameters != nil && len(obj.Spec.Parameters.RawMessage) > 0 {
+		raw = obj.Spec.Parameters.RawMessage
+	}
+
+	raw, err := mergeParameters(raw, resolved)
+	if err != nil {
+		return params, err
+	}
+
+	if err := params.UnmarshalJSON(raw); err != nil {
+		return params, err
+	}
+
+	if obj.Spec.Recording != nil {
+		if err := mergeRecordingParameters(guac, obj, &params); err != nil {
+			return params, err
+		}
+	}
+
+	return params, nil
+}
+
+// connectionAttributes returns the attributes of a connection. Connections
+// of a guacd pool are routed to the service of the pool, all others use the
+// guacd of the instance and get no attributes.
+func (r *Reconciler) connectionAttributes(obj *v1alpha1.Connection) (gen.ConnectionAttributes, error) {
+	attributes := gen.ConnectionAttributes{}
+
+	// Route connection to a guacd pool.
+	if obj.Spec.GuacdPool != nil {
+		pool := *obj.Spec.GuacdPool
+
+		defined := slices.ContainsFunc(r.guac.Spec.GuacdPools, func(p v1alpha1.GuacdPool) bool {
+			return p.Name == pool
+		})
+		if !defined {
+			return attributes, fmt.Errorf("guacd pool %s not defined in instance %s", pool, r.guac.Name)
+		}
+
+		hostname := v1alpha1.GuacdPoolServiceName(r.guac.Name, pool)
+		port := strconv.Itoa(guacdPort)
+
+		attributes.GuacdHostname = &hostname
+		attributes.GuacdPort = &port
+
+		// Pools inherit the TLS configuration of the default deployment
+		// and therefore only accept encrypted connections as well.
+		if r.guac.Spec.Guacd != nil && r.guac.Spec.Guacd.TLS != nil {
+			encryption := gen.Ssl
+			attributes.GuacdEncryption = &encryption
+		}
+	}
+
+	return attributes, nil
+}
+
+// recordingPath is the path of recordings expected by the history
+// recording storage extension.
+const recordingPath = "${HISTORY_PATH}/${HISTORY_UUID}"
+
+// mergeParameters merges resolved parameters into raw connection parameters.
+func mergeParameters(raw []byte, resolved map[string]string) ([]byte, error) {
+	if len(resolved) == 0 {
+		return raw, nil
+	}
+
+	params := map[string]any{}
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, err
+	}
+
+	for k, v := range resolved {
+		params[k] = v
+	}
+
+	return json.Marshal(params)
+}
+
+// mergeRecordingParameters merges the recording configuration into the
+// connection parameters.
+func mergeRecordingParameters(guac *v1alpha1.Guacamole, obj *v1alpha1.Connection, params *gen.ConnectionParameters) error {
+	if guac.Spec.Recording == nil {
+		return fmt.Errorf("recording not configured in instance %s", guac.Name)
+	}
+
+	recording := obj.Spec.Recording
+	path := recordingPath
+
+	// Default to the connection name so retention overrides
+	// can match recordings by name.
+	name := recording.Name
+	if name == "" {
+		name = obj.Name
+	}
+
+	// Recording parameters are identical for all protocols,
+	// non-RDP protocols use the VNC definition.
+	if obj.Spec.Protocol == gen.Rdp {
+		rdp := gen.ConnectionParametersRDP{
+			RecordingPath:       &path,
+			RecordingName:       &name,
+			CreateRecordingPath: ptr.To(gen.ConnectionParametersRDPCreateRecordingPathTrue),
+		}
+
+		if recording.IncludeKeys {
+			rdp.RecordingIncludeKeys = ptr.To(gen.ConnectionParametersRDPRecordingIncludeKeysTrue)
+		}
+
+		if recording.ExcludeOutput {
+			rdp.RecordingExcludeOutput = ptr.To(gen.ConnectionParametersRDPRecordingExcludeOutputTrue)
+		}
+
+		if recording.ExcludeMouse {
+			rdp.RecordingExcludeMouse = ptr.To(gen.ConnectionParametersRDPRecordingExcludeMouseTrue)
+		}
+
+		return params.MergeConnectionParametersRDP(rdp)
+	}
+
+	vnc := gen.ConnectionParametersVNC{
+		RecordingPath:       &path,
+		RecordingName:       &name,
+		CreateRecordingPath: ptr.To(gen.ConnectionParametersVNCCreateRecordingPathTrue),
+	}
+
+	if recording.IncludeKeys {
+		vnc.RecordingIncludeKeys = ptr.To(gen.ConnectionParametersVNCRecordingIncludeKeysTrue)
+	}
+
+	if recording.ExcludeOutput {
+		vnc.RecordingExcludeOutput = ptr.To(gen.ConnectionParametersVNCRecordingExcludeOutputTrue)
+	}
+
+	if recording.ExcludeMouse {
+		vnc.RecordingExcludeMouse = ptr.To(gen.ConnectionParametersVNCRecordingExcludeMouseTrue)
+	}
+
+	return params.MergeConnectionParametersVNC(vnc)
+}
+
 // Delete deletes the connection resource.
 func (r *Reconciler) Delete(ctx context.Context, obj *v1alpha1.Connection) error {
 	// Nothing to do.
@@ -0,0 +1,501 @@
+package transformer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+	"github.com/guacamole-operator/guacamole-operator/internal/jsonauth"
+)
+
+const (
+	casVolumeName    = "cas"
+	casMountPath     = "/etc/guacamole/cas"
+	casClearPassFile = "clearpass.key"
+
+	jsonAuthSecretName = "guacamole-json-auth"
+)
+
+// duoUniversalPromptVersion is the first Guacamole version using the
+// Duo Universal Prompt.
+var duoUniversalPromptVersion = version.MustParseGeneric("1.6.0")
+
+// applyAuthConfiguration converts the typed authentication methods
+// to environment variables of the Guacamole container. The extension
+// priority also covers the given (resolved) extensions.
+func applyAuthConfiguration(guac *v1alpha1.Guacamole, extensions []v1alpha1.Extension, m *manifest.Objects) error {
+	auth := &guac.Spec.Auth
+
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		var envs []corev1.EnvVar
+
+		if auth.SAML != nil {
+			envs = append(envs, samlEnvVars(auth.SAML)...)
+		}
+
+		if auth.CAS != nil {
+			envs = append(envs, casEnvVars(auth.CAS)...)
+
+			if auth.CAS.ClearPassKeyRef != nil {
+				ensureVolume(deployment, corev1.Volume{
+					Name: casVolumeName,
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{
+							SecretName: auth.CAS.ClearPassKeyRef.Name,
+							Items: []corev1.KeyToPath{
+								{
+									Key:  auth.CAS.ClearPassKeyRef.Key,
+									Path: casClearPassFile,
+								},
+							},
+						},
+					},
+				})
+
+				ensureContainerVolumeMount(deployment, "guacamole", corev1.VolumeMount{
+					Name:      casVolumeName,
+					MountPath: casMountPath,
+					ReadOnly:  true,
+				})
+			}
+		}
+
+		if auth.Header != nil {
+			envs = append(envs, headerEnvVars(auth.Header)...)
+		}
+
+		if auth.RADIUS != nil {
+			envs = append(envs, radiusEnvVars(auth.RADIUS)...)
+		}
+
+		if auth.MFA != nil && auth.MFA.TOTP != nil {
+			envs = append(envs, totpEnvVars(auth.MFA.TOTP)...)
+		}
+
+		if auth.MFA != nil && auth.MFA.Duo != nil {
+			duoEnvs, err := duoEnvVars(auth.MFA.Duo, deployment.Spec.Template.Spec.Containers[0].Image)
+			if err != nil {
+				return err
+			}
+
+			envs = append(envs, duoEnvs...)
+		}
+
+		// Priority set via additional settings takes precedence.
+		_, ok := normalizeSettings(guac.Spec.AdditionalSettings)["EXTENSION_PRIORITY"]
+		if priority := extensionPriority(auth, extensions...); priority != "" && !ok {
+			envs = append(envs, corev1.EnvVar{Name: "EXTENSION_PRIORITY", Value: priority})
+		}
+
+		for _, env := range envs {
+			deployment.Spec.Template.Spec.Containers[0].Env = ensureEnvVar(deployment.Spec.Template.Spec.Containers[0].Env, env)
+		}
+
+		return nil
+	})
+}
+
+// applyJSONAuthConfiguration configures the encrypted JSON authentication.
+// Unless an existing secret is referenced, a secret with a generated key
+// is added to the manifest. The key is kept once generated.
+func applyJSONAuthConfiguration(ctx context.Context, c client.Client, guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	jsonAuth := guac.Spec.Auth.JSON
+
+	if jsonAuth.SecretRef == nil {
+		var secret corev1.Secret
+		err := c.Get(ctx, types.NamespacedName{Name: jsonAuth.SecretName(guac.Name), Namespace: guac.Namespace}, &secret)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("error getting JSON auth secret: %w", err)
+		}
+
+		key := string(secret.Data[v1alpha1.JSONAuthSecretKey])
+		if _, err := jsonauth.ParseKey(key); err != nil {
+			if key, err = jsonauth.GenerateKey(); err != nil {
+				return err
+			}
+		}
+
+		if err := applyJSONAuthSecret(key, m); err != nil {
+			return err
+		}
+	}
+
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		deployment.Spec.Template.Spec.Containers[0].Env = ensureEnvVar(deployment.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+			Name: "JSON_SECRET_KEY",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: jsonAuth.SecretName(guac.Name),
+					},
+					Key: v1alpha1.JSONAuthSecretKey,
+				},
+			},
+		})
+
+		return nil
+	})
+}
+
+// applyJSONAuthSecret adds the secret holding the JSON auth key to the manifest.
+func applyJSONAuthSecret(key string, m *manifest.Objects) error {
+	secret := corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: jsonAuthSecretName,
+			Labels: map[string]string{
+				nameLabel: GuacamoleDeploymentName,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			v1alpha1.JSONAuthSecretKey: []byte(key),
+		},
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&secret)
+	if err != nil {
+		return err
+	}
+
+	obj, err := manifest.NewObject(&unstructured.Unstructured{Object: u})
+	if err != nil {
+		return err
+	}
+
+	m.Items = append(m.Items, obj)
+
+	return nil
+}
+
+// Priority of single sign-on methods without priority, so that they are
+// loaded after all other extensions.
+const ssoPriority = 1
+
+// authExtension defines the extension of a configured authentication method.
+type authExtension struct {
+	namespace string
+	priority  *int32
+	sso       bool
+	// Settings of which at least one is required by the Guacamole
+	// image to load the extension.
+	required []string
+}
+
+// authExtensions returns the extensions of the configured authentication
+// methods. Single sign-on methods are ordered last.
+func authExtensions(auth *v1alpha1.Auth) []authExtension {
+	var extensions []authExtension
+
+	add := func(namespace string, priority v1alpha1.ExtensionPriority, sso bool, required ...string) {
+		extensions = append(extensions, authExtension{
+			namespace: namespace,
+			priority:  priority.Priority,
+			sso:       sso,
+			required:  required,
+		})
+	}
+
+	if auth.Postgres != nil {
+		add("postgresql", auth.Postgres.ExtensionPriority, false, "POSTGRESQL_DATABASE", "POSTGRES_DATABASE")
+	}
+
+	if auth.MySQL != nil {
+		add("mysql", auth.MySQL.ExtensionPriority, false, "MYSQL_DATABASE")
+	}
+
+	if auth.SQLServer != nil {
+		add("sqlserver", auth.SQLServer.ExtensionPriority, false, "SQLSERVER_DATABASE")
+	}
+
+	if auth.LDAP != nil {
+		add("ldap", auth.LDAP.ExtensionPriority, false, "LDAP_HOSTNAME")
+	}
+
+	if auth.RADIUS != nil {
+		add("radius", auth.RADIUS.ExtensionPriority, false, "RADIUS_SHARED_SECRET")
+	}
+
+	if auth.Header != nil {
+		add("header", auth.Header.ExtensionPriority, false, "HEADER_ENABLED")
+	}
+
+	if auth.JSON != nil {
+		add("json", auth.JSON.ExtensionPriority, false, "JSON_SECRET_KEY")
+	}
+
+	if auth.MFA != nil && auth.MFA.TOTP != nil {
+		add("totp", auth.MFA.TOTP.ExtensionPriority, false, "TOTP_ENABLED")
+	}
+
+	if auth.MFA != nil && auth.MFA.Duo != nil {
+		add("duo", auth.MFA.Duo.ExtensionPriority, false, "DUO_API_HOSTNAME")
+	}
+
+	if auth.OIDC != nil {
+		add("openid", auth.OIDC.ExtensionPriority, true, "OPENID_AUTHORIZATION_ENDPOINT")
+	}
+
+	if auth.SAML != nil {
+		add("saml", auth.SAML.ExtensionPriority, true, "SAML_IDP_METADATA_URL", "SAML_IDP_URL")
+	}
+
+	if auth.CAS != nil {
+		add("cas", auth.CAS.ExtensionPriority, true, "CAS_AUTHORIZATION_ENDPOINT")
+	}
+
+	return extensions
+}
+
+// extensionPriority returns the priority of extensions. Extensions are
+// ordered by priority, all others (`*`) are loaded at priority 0. Single
+// sign-on methods are ordered after all other methods by default, so that
+// the login form is shown with links to the identity providers.
+func extensionPriority(auth *v1alpha1.Auth, extensions ...v1alpha1.Extension) string {
+	type entry struct {
+		namespace string
+		priority  int32
+	}
+
+	var entries []entry
+
+	seen := map[string]struct{}{}
+
+	add := func(namespace string, priority int32) {
+		if _, ok := seen[namespace]; ok {
+			return
+		}
+
+		seen[namespace] = struct{}{}
+		entries = append(entries, entry{namespace: namespace, priority: priority})
+	}
+
+	for _, e := range authExtensions(auth) {
+		switch {
+		case e.priority != nil:
+			add(e.namespace, *e.priority)
+		case e.sso:
+			add(e.namespace, ssoPriority)
+		}
+	}
+
+	for _, e := range extensions {
+		if e.Priority != nil && e.Namespace != "" {
+			add(e.Namespace, *e.Priority)
+		}
+	}
+
+	if len(entries) == 0 {
+		return ""
+	}
+
+	// Ordered after extensions with explicit priority 0.
+	entries = append(entries, entry{namespace: "*"})
+
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		return cmp.Compare(a.priority, b.priority)
+	})
+
+	namespaces := make([]string, 0, len(entries))
+	for _, e := range entries {
+		namespaces = append(namespaces, e.namespace)
+	}
+
+	return strings.Join(namespaces, ", ")
+}
+
+// applyAuthExtensionStatus reports whether the extensions of configured
+// authentication methods are loaded. The Guacamole image only loads
+// extensions whose required settings are set.
+func applyAuthExtensionStatus(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	extensions := authExtensions(&guac.Spec.Auth)
+	if len(extensions) == 0 {
+		meta.RemoveStatusCondition(&guac.Status.Conditions, string(v1alpha1.GuacamoleAuthExtensionsLoaded))
+		return nil
+	}
+
+	var missing []string
+
+	err := updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		envs := deployment.Spec.Template.Spec.Containers[0].Env
+
+		for _, e := range extensions {
+			if !slices.ContainsFunc(envs, func(env corev1.EnvVar) bool { return slices.Contains(e.required, env.Name) }) {
+				missing = append(missing, fmt.Sprintf("%s (requires %s)", e.namespace, strings.Join(e.required, " or ")))
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		guac.Status.MarkAuthExtensionsNotLoaded("Extensions of configured authentication methods are not loaded: " + strings.Join(missing, ", ") + ".")
+	} else {
+		guac.Status.MarkAuthExtensionsLoaded()
+	}
+
+	return nil
+}
+
+func samlEnvVars(saml *v1alpha1.SAML) []corev1.EnvVar {
+	var envs []corev1.EnvVar
+
+	envs = appendEnvVar(envs, "SAML_IDP_METADATA_URL", saml.IdPMetadataURL)
+	envs = appendEnvVar(envs, "SAML_IDP_URL", saml.IdPURL)
+	envs = appendEnvVar(envs, "SAML_ENTITY_ID", saml.EntityID)
+	envs = appendEnvVar(envs, "SAML_CALLBACK_URL", saml.CallbackURL)
+	envs = appendEnvVar(envs, "SAML_GROUP_ATTRIBUTE", saml.GroupAttribute)
+	envs = appendBoolEnvVar(envs, "SAML_STRICT", saml.Strict)
+	envs = appendBoolEnvVar(envs, "SAML_COMPRESS_REQUEST", saml.CompressRequest)
+	envs = appendBoolEnvVar(envs, "SAML_COMPRESS_RESPONSE", saml.CompressResponse)
+
+	return envs
+}
+
+func casEnvVars(cas *v1alpha1.CAS) []corev1.EnvVar {
+	var envs []corev1.EnvVar
+
+	envs = appendEnvVar(envs, "CAS_AUTHORIZATION_ENDPOINT", cas.AuthorizationEndpoint)
+	envs = appendEnvVar(envs, "CAS_REDIRECT_URI", cas.RedirectURI)
+	envs = appendEnvVar(envs, "CAS_GROUP_ATTRIBUTE", cas.GroupAttribute)
+	envs = appendEnvVar(envs, "CAS_GROUP_FORMAT", cas.GroupFormat)
+	envs = appendEnvVar(envs, "CAS_GROUP_LDAP_BASE_DN", cas.GroupLDAPBaseDN)
+	envs = appendEnvVar(envs, "CAS_GROUP_LDAP_ATTRIBUTE", cas.GroupLDAPAttribute)
+
+	if cas.ClearPassKeyRef != nil {
+		envs = appendEnvVar(envs, "CAS_CLEARPASS_KEY", casMountPath+"/"+casClearPassFile)
+	}
+
+	return envs
+}
+
+func headerEnvVars(header *v1alpha1.Header) []corev1.EnvVar {
+	name := header.Name
+	if name == "" {
+		name = "REMOTE_USER"
+	}
+
+	return []corev1.EnvVar{
+		{Name: "HEADER_ENABLED", Value: "true"},
+		{Name: "HTTP_AUTH_HEADER", Value: name},
+	}
+}
+
+func radiusEnvVars(radius *v1alpha1.RADIUS) []corev1.EnvVar {
+	var envs []corev1.EnvVar
+
+	envs = appendEnvVar(envs, "RADIUS_HOSTNAME", radius.Hostname)
+	envs = appendEnvVar(envs, "RADIUS_AUTH_PROTOCOL", radius.AuthProtocol)
+	envs = appendIntEnvVar(envs, "RADIUS_AUTH_PORT", radius.AuthPort)
+	envs = appendIntEnvVar(envs, "RADIUS_RETRIES", radius.Retries)
+	envs = appendIntEnvVar(envs, "RADIUS_TIMEOUT", radius.Timeout)
+	envs = appendEnvVar(envs, "RADIUS_NAS_IP", radius.NASIP)
+
+	secretRef := radius.SharedSecretRef
+
+	return append(envs, corev1.EnvVar{
+		Name: "RADIUS_SHARED_SECRET",
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &secretRef,
+		},
+	})
+}
+
+func totpEnvVars(totp *v1alpha1.TOTP) []corev1.EnvVar {
+	envs := []corev1.EnvVar{
+		{Name: "TOTP_ENABLED", Value: "true"},
+	}
+
+	envs = appendEnvVar(envs, "TOTP_ISSUER", totp.Issuer)
+	envs = appendIntEnvVar(envs, "TOTP_DIGITS", totp.Digits)
+	envs = appendIntEnvVar(envs, "TOTP_PERIOD", totp.Period)
+	envs = appendEnvVar(envs, "TOTP_MODE", totp.Mode)
+
+	return envs
+}
+
+// duoEnvVars returns the Duo configuration for the Guacamole version of
+// the image. Guacamole 1.6 replaced the Web SDK with the Universal Prompt
+// and renamed the keys. Images without a version tag are assumed current.
+func duoEnvVars(duo *v1alpha1.Duo, image string) ([]corev1.EnvVar, error) {
+	integrationKeyRef := duo.IntegrationKeyRef
+	secretKeyRef := duo.SecretKeyRef
+
+	envs := []corev1.EnvVar{
+		{Name: "DUO_API_HOSTNAME", Value: duo.APIHostname},
+	}
+
+	v, err := version.ParseGeneric(imageTag(image))
+	if err != nil || !v.LessThan(duoUniversalPromptVersion) {
+		if duo.RedirectURI == "" {
+			return nil, fmt.Errorf("duo requires a redirect URI with Guacamole %s and later", duoUniversalPromptVersion)
+		}
+
+		return append(envs,
+			corev1.EnvVar{Name: "DUO_REDIRECT_URI", Value: duo.RedirectURI},
+			corev1.EnvVar{Name: "DUO_CLIENT_ID", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &integrationKeyRef}},
+			corev1.EnvVar{Name: "DUO_CLIENT_SECRET", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &secretKeyRef}},
+		), nil
+	}
+
+	if duo.ApplicationKeyRef == nil {
+		return nil, fmt.Errorf("duo requires an application key with Guacamole %s", v)
+	}
+
+	applicationKeyRef := *duo.ApplicationKeyRef
+
+	return append(envs,
+		corev1.EnvVar{Name: "DUO_INTEGRATION_KEY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &integrationKeyRef}},
+		corev1.EnvVar{Name: "DUO_SECRET_KEY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &secretKeyRef}},
+		corev1.EnvVar{Name: "DUO_APPLICATION_KEY", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &applicationKeyRef}},
+	), nil
+}
+
+// appendEnvVar appends an environment variable if the value is set.
+func appendEnvVar(envs []corev1.EnvVar, name, value string) []corev1.EnvVar {
+	if value == "" {
+		return envs
+	}
+
+	return append(envs, corev1.EnvVar{Name: name, Value: value})
+}
+
+// appendBoolEnvVar appends an environment variable if the value is set.
+func appendBoolEnvVar(envs []corev1.EnvVar, name string, value *bool) []corev1.EnvVar {
+	if value == nil {
+		return envs
+	}
+
+	return append(envs, corev1.EnvVar{Name: name, Value: strconv.FormatBool(*value)})
+}
+
+// appendIntEnvVar appends an environment variable if the value is set.
+func appendIntEnvVar(envs []corev1.EnvVar, name string, value *int32) []corev1.EnvVar {
+	if value == nil {
+		return envs
+	}
+
+	return append(envs, corev1.EnvVar{Name: name, Value: strconv.FormatInt(int64(*value), 10)})
+}
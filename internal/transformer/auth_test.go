@@ -0,0 +1,189 @@
+package transformer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+func TestExtensionPriority(t *testing.T) {
+	tests := []struct {
+		name string
+		auth v1alpha1.Auth
+		want string
+	}{
+		{
+			name: "no sso",
+			auth: v1alpha1.Auth{Postgres: &v1alpha1.Postgres{}, Header: &v1alpha1.Header{}},
+			want: "",
+		},
+		{
+			name: "oidc",
+			auth: v1alpha1.Auth{Postgres: &v1alpha1.Postgres{}, OIDC: &v1alpha1.OIDC{}},
+			want: "*, openid",
+		},
+		{
+			name: "multiple sso",
+			auth: v1alpha1.Auth{CAS: &v1alpha1.CAS{}, SAML: &v1alpha1.SAML{}, OIDC: &v1alpha1.OIDC{}},
+			want: "*, openid, saml, cas",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extensionPriority(&tt.auth); got != tt.want {
+				t.Errorf("extensionPriority() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtensionPriorityExplicit(t *testing.T) {
+	first := v1alpha1.ExtensionPriority{Priority: ptr.To[int32](-1)}
+	last := v1alpha1.ExtensionPriority{Priority: ptr.To[int32](2)}
+
+	tests := []struct {
+		name       string
+		auth       v1alpha1.Auth
+		extensions []v1alpha1.Extension
+		want       string
+	}{
+		{
+			name: "database first",
+			auth: v1alpha1.Auth{Postgres: &v1alpha1.Postgres{ExtensionPriority: first}, OIDC: &v1alpha1.OIDC{}},
+			want: "postgresql, *, openid",
+		},
+		{
+			name: "sso before others",
+			auth: v1alpha1.Auth{Postgres: &v1alpha1.Postgres{}, SAML: &v1alpha1.SAML{ExtensionPriority: first}},
+			want: "saml, *",
+		},
+		{
+			name: "extensions",
+			auth: v1alpha1.Auth{Postgres: &v1alpha1.Postgres{}, OIDC: &v1alpha1.OIDC{}},
+			extensions: []v1alpha1.Extension{
+				{URI: "https://example.com/custom.jar", Namespace: "custom", ExtensionPriority: first},
+				{URI: "https://example.com/other.jar", Namespace: "other", ExtensionPriority: last},
+				{URI: "https://example.com/unordered.jar", Namespace: "unordered"},
+			},
+			want: "custom, *, openid, other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extensionPriority(&tt.auth, tt.extensions...); got != tt.want {
+				t.Errorf("extensionPriority() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyAuthExtensionStatus(t *testing.T) {
+	m, err := manifest.ParseObjects(context.Background(), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: guacamole
+spec:
+  template:
+    spec:
+      containers:
+        - name: guacamole
+          env:
+            - name: POSTGRESQL_DATABASE
+              value: guacamole
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	guac := &v1alpha1.Guacamole{
+		Spec: v1alpha1.GuacamoleSpec{
+			Auth: v1alpha1.Auth{Postgres: &v1alpha1.Postgres{}, OIDC: &v1alpha1.OIDC{}},
+		},
+	}
+
+	if err := applyAuthExtensionStatus(guac, m); err != nil {
+		t.Fatal(err)
+	}
+
+	condition := meta.FindStatusCondition(guac.Status.Conditions, string(v1alpha1.GuacamoleAuthExtensionsLoaded))
+	if condition == nil || condition.Status != metav1.ConditionFalse {
+		t.Fatalf("expected condition to be false, got %v", condition)
+	}
+
+	if want := "openid (requires OPENID_AUTHORIZATION_ENDPOINT)"; !strings.Contains(condition.Message, want) {
+		t.Errorf("expected message to contain %q, got %q", want, condition.Message)
+	}
+
+	guac.Spec.Auth.OIDC = nil
+
+	if err := applyAuthExtensionStatus(guac, m); err != nil {
+		t.Fatal(err)
+	}
+
+	if !meta.IsStatusConditionTrue(guac.Status.Conditions, string(v1alpha1.GuacamoleAuthExtensionsLoaded)) {
+		t.Error("expected condition to be true")
+	}
+}
+
+func TestDuoEnvVars(t *testing.T) {
+	duo := &v1alpha1.Duo{
+		APIHostname:       "api-1234.duosecurity.com",
+		IntegrationKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "duo"}, Key: "integration-key"},
+		SecretKeyRef:      corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "duo"}, Key: "secret-key"},
+	}
+
+	names := func(envs []corev1.EnvVar) []string {
+		var names []string
+		for _, env := range envs {
+			names = append(names, env.Name)
+		}
+
+		return names
+	}
+
+	tests := []struct {
+		image string
+		want  []string
+	}{
+		{"docker.io/guacamole/guacamole:1.6.0", []string{"DUO_API_HOSTNAME", "DUO_REDIRECT_URI", "DUO_CLIENT_ID", "DUO_CLIENT_SECRET"}},
+		{"docker.io/guacamole/guacamole:latest", []string{"DUO_API_HOSTNAME", "DUO_REDIRECT_URI", "DUO_CLIENT_ID", "DUO_CLIENT_SECRET"}},
+		{"docker.io/guacamole/guacamole:1.5.5", []string{"DUO_API_HOSTNAME", "DUO_INTEGRATION_KEY", "DUO_SECRET_KEY", "DUO_APPLICATION_KEY"}},
+	}
+
+	// Guacamole before 1.6 requires an application key.
+	if _, err := duoEnvVars(duo, tests[2].image); err == nil {
+		t.Error("expected error for missing application key")
+	}
+
+	duo.ApplicationKeyRef = &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "duo"}, Key: "application-key"}
+
+	// Guacamole 1.6 and later requires a redirect URI.
+	if _, err := duoEnvVars(duo, tests[0].image); err == nil {
+		t.Error("expected error for missing redirect URI")
+	}
+
+	duo.RedirectURI = "https://guacamole.example.com"
+
+	for _, tt := range tests {
+		envs, err := duoEnvVars(duo, tt.image)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := names(envs); !cmp.Equal(tt.want, got) {
+			t.Errorf("%s: unexpected diff (-want +got):\n%s", tt.image, cmp.Diff(tt.want, got))
+		}
+	}
+}
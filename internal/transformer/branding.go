@@ -0,0 +1,193 @@
+package transformer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+	"github.com/guacamole-operator/guacamole-operator/internal/branding"
+)
+
+const (
+	brandingName               = "guacamole-branding"
+	brandingVolumeName         = "branding"
+	brandingKey                = "branding.jar"
+	brandingChecksumAnnotation = "guacamole-operator.github.io/branding-checksum"
+
+	// Extensions of the home directory template are copied by the entrypoint.
+	// GUACAMOLE_HOME=/tmp/guacamole required.
+	brandingMountPath = "/tmp/guacamole/extensions/guacamole-branding.jar"
+)
+
+// applyBranding packages the branding content into an extension, stores it
+// in a ConfigMap and mounts it into the extensions of the web application.
+// Pods are rolled whenever the packaged extension changes.
+func applyBranding(ctx context.Context, c client.Client, guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	content, err := brandingContent(ctx, c, guac.Namespace, guac.Spec.Branding)
+	if err != nil {
+		return err
+	}
+
+	data, err := branding.Build(content)
+	if err != nil {
+		return fmt.Errorf("error building branding extension: %w", err)
+	}
+
+	if err := applyBrandingConfigMap(data, m); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		ensureVolume(deployment, corev1.Volume{
+			Name: brandingVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: brandingName + "-" + guac.Name,
+					},
+				},
+			},
+		})
+
+		// Mounted via sub path, which is not updated in running pods.
+		ensureContainerVolumeMount(deployment, "guacamole", corev1.VolumeMount{
+			Name:      brandingVolumeName,
+			ReadOnly:  true,
+			MountPath: brandingMountPath,
+			SubPath:   brandingKey,
+		})
+
+		setPodAnnotation(&deployment.Spec.Template, brandingChecksumAnnotation, hex.EncodeToString(sum[:]))
+
+		return nil
+	})
+}
+
+// applyBrandingConfigMap adds the ConfigMap holding the branding
+// extension to the manifest.
+func applyBrandingConfigMap(data []byte, m *manifest.Objects) error {
+	configMap := corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: brandingName,
+			Labels: map[string]string{
+				nameLabel: GuacamoleDeploymentName,
+			},
+		},
+		BinaryData: map[string][]byte{
+			brandingKey: data,
+		},
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&configMap)
+	if err != nil {
+		return err
+	}
+
+	obj, err := manifest.NewObject(&unstructured.Unstructured{Object: u})
+	if err != nil {
+		return err
+	}
+
+	m.Items = append(m.Items, obj)
+
+	return nil
+}
+
+// brandingContent reads the referenced branding content.
+func brandingContent(ctx context.Context, c client.Client, namespace string, b *v1alpha1.Branding) (*branding.Content, error) {
+	content := &branding.Content{
+		Title:        b.Title,
+		Translations: map[string][]byte{},
+	}
+
+	if b.Logo != nil {
+		data, ok, err := configMapValue(ctx, c, namespace, b.Logo)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			content.Logo = &branding.File{Name: b.Logo.Key, Data: data}
+		}
+	}
+
+	if b.Background != nil {
+		data, ok, err := configMapValue(ctx, c, namespace, b.Background)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			content.Background = &branding.File{Name: b.Background.Key, Data: data}
+		}
+	}
+
+	if b.CSS != nil {
+		data, _, err := configMapValue(ctx, c, namespace, b.CSS)
+		if err != nil {
+			return nil, err
+		}
+
+		content.CSS = string(data)
+	}
+
+	for _, t := range b.Translations {
+		data, ok, err := configMapValue(ctx, c, namespace, &t.ConfigMapKeyRef)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			content.Translations[t.Language] = data
+		}
+	}
+
+	return content, nil
+}
+
+// configMapValue returns the value of a ConfigMap key, which can be either
+// textual or binary data. Missing optional values are reported as not found.
+func configMapValue(ctx context.Context, c client.Client, namespace string, ref *corev1.ConfigMapKeySelector) ([]byte, bool, error) {
+	optional := ref.Optional != nil && *ref.Optional
+
+	var configMap corev1.ConfigMap
+	if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: namespace}, &configMap); err != nil {
+		if apierrors.IsNotFound(err) && optional {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("error getting ConfigMap %s: %w", ref.Name, err)
+	}
+
+	if value, ok := configMap.Data[ref.Key]; ok {
+		return []byte(value), true, nil
+	}
+
+	if value, ok := configMap.BinaryData[ref.Key]; ok {
+		return value, true, nil
+	}
+
+	if optional {
+		return nil, false, nil
+	}
+
+	return nil, false, fmt.Errorf("key %s not found in ConfigMap %s", ref.Key, ref.Name)
+}
@@ -0,0 +1,134 @@
+package transformer
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+const extensionCatalogKind = "ExtensionCatalog"
+
+// extensionCatalog lists the well-known extensions of a Guacamole version.
+// It is shipped with the channel package and is not applied to the cluster.
+type extensionCatalog struct {
+	Extensions []catalogExtension `json:"extensions,omitempty"`
+}
+
+// catalogExtension defines an extension of the catalog.
+type catalogExtension struct {
+	// Name referenced by `spec.extensions[].name`.
+	Name string `json:"name"`
+	// Namespace of the extension as declared in its manifest.
+	Namespace string `json:"namespace,omitempty"`
+	// Source of the extension in go-getter format.
+	URI string `json:"uri"`
+	// Expected SHA-256 digest (hex) of the downloaded file.
+	SHA256 string `json:"sha256,omitempty"`
+	// Properties required by the extension.
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// extractExtensionCatalog removes the extension catalog from the manifest
+// and returns it. The catalog is empty if the package does not ship one.
+func extractExtensionCatalog(m *manifest.Objects) (*extensionCatalog, error) {
+	catalog := &extensionCatalog{}
+
+	for idx, item := range m.Items {
+		if item.Kind != extensionCatalogKind {
+			continue
+		}
+
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredObject().Object, catalog)
+		if err != nil {
+			return nil, fmt.Errorf("error converting extension catalog from unstructured: %w", err)
+		}
+
+		m.Items = slices.Delete(m.Items, idx, idx+1)
+
+		break
+	}
+
+	return catalog, nil
+}
+
+// resolveExtensions replaces extensions selected by name with the source
+// of the catalog and returns the properties they require.
+func resolveExtensions(catalog *extensionCatalog, extensions []v1alpha1.Extension) ([]v1alpha1.Extension, map[string]string, error) {
+	resolved := make([]v1alpha1.Extension, 0, len(extensions))
+	properties := map[string]string{}
+
+	for _, e := range extensions {
+		if e.Name == "" {
+			resolved = append(resolved, e)
+			continue
+		}
+
+		idx := slices.IndexFunc(catalog.Extensions, func(c catalogExtension) bool { return c.Name == e.Name })
+		if idx < 0 {
+			return nil, nil, fmt.Errorf("extension %q not found in catalog, available: %v", e.Name, catalog.names())
+		}
+
+		entry := catalog.Extensions[idx]
+
+		e.Name = ""
+		e.URI = entry.URI
+
+		if e.Namespace == "" {
+			e.Namespace = entry.Namespace
+		}
+
+		if e.Priority != nil && e.Namespace == "" {
+			return nil, nil, fmt.Errorf("extension %q has no namespace in catalog, set namespace to use priority", entry.Name)
+		}
+
+		if e.SHA256 == "" {
+			e.SHA256 = entry.SHA256
+		}
+
+		maps.Copy(properties, entry.Properties)
+
+		resolved = append(resolved, e)
+	}
+
+	return resolved, properties, nil
+}
+
+func (c *extensionCatalog) names() []string {
+	names := make([]string, 0, len(c.Extensions))
+	for _, e := range c.Extensions {
+		names = append(names, e.Name)
+	}
+
+	return names
+}
+
+// applyExtensionProperties configures the properties required by catalog
+// extensions. Properties already set as environment variables, e.g. by
+// additional settings, take precedence.
+func applyExtensionProperties(properties map[string]string, m *manifest.Objects) error {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		container := &deployment.Spec.Template.Spec.Containers[0]
+		settings := normalizeSettings(properties)
+
+		for _, name := range slices.Sorted(maps.Keys(settings)) {
+			if slices.ContainsFunc(container.Env, func(env corev1.EnvVar) bool { return env.Name == name }) {
+				continue
+			}
+
+			container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: settings[name]})
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+package transformer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+func TestExtractExtensionCatalog(t *testing.T) {
+	// Catalogs shipped with the channel packages.
+	files, err := filepath.Glob("../../channels/packages/guacamole/*/extensions.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) == 0 {
+		t.Fatal("expected extension catalogs in channel packages")
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m, err := manifest.ParseObjects(context.Background(), string(data))
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		catalog, err := extractExtensionCatalog(m)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+
+		if len(m.Items) != 0 {
+			t.Errorf("%s: expected catalog to be removed from manifest", file)
+		}
+
+		for _, name := range []string{"ban", "display-statistics", "history-recording-storage", "quickconnect", "totp"} {
+			if _, _, err := resolveExtensions(catalog, []v1alpha1.Extension{{Name: name}}); err != nil {
+				t.Errorf("%s: %v", file, err)
+			}
+		}
+	}
+}
+
+func TestResolveExtensions(t *testing.T) {
+	catalog := &extensionCatalog{
+		Extensions: []catalogExtension{
+			{
+				Name: "history-recording-storage",
+				URI:  "https://example.com/guacamole-history-recording-storage-1.6.0.tar.gz//guacamole-history-recording-storage-1.6.0",
+				Properties: map[string]string{
+					"recording-search-path": "/var/lib/guacamole/recordings",
+				},
+			},
+		},
+	}
+
+	extensions := []v1alpha1.Extension{
+		{URI: "https://example.com/branding.jar"},
+		{Name: "history-recording-storage"},
+	}
+
+	got, properties, err := resolveExtensions(catalog, extensions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []v1alpha1.Extension{
+		{URI: "https://example.com/branding.jar"},
+		{URI: "https://example.com/guacamole-history-recording-storage-1.6.0.tar.gz//guacamole-history-recording-storage-1.6.0"},
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+
+	wantProperties := map[string]string{"recording-search-path": "/var/lib/guacamole/recordings"}
+	if !cmp.Equal(wantProperties, properties) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantProperties, properties))
+	}
+
+	if _, _, err := resolveExtensions(catalog, []v1alpha1.Extension{{Name: "unknown"}}); err == nil {
+		t.Error("expected error for unknown extension")
+	}
+}
@@ -0,0 +1,194 @@
+package transformer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"maps"
+	"slices"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+// Pod annotation holding a checksum over all Secrets and ConfigMaps
+// referenced by a pod. Changes of referenced objects roll the pods.
+const configChecksumAnnotation = "guacamole-operator.github.io/config-checksum"
+
+// applyConfigChecksums annotates the pod templates of all deployments
+// with a checksum over the Secrets and ConfigMaps they reference. Objects
+// of the manifest itself are skipped, as their content is only applied
+// after the transformation.
+func applyConfigChecksums(ctx context.Context, c client.Client, guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	var names []string
+
+	managed := map[string]struct{}{}
+
+	for _, item := range m.Items {
+		switch item.Kind {
+		case "Deployment":
+			names = append(names, item.GetName())
+		case "Secret", "ConfigMap":
+			// Instance name is added after the checksums.
+			managed[item.Kind+"/"+item.GetName()+"-"+guac.Name] = struct{}{}
+		}
+	}
+
+	unmanaged := func(kind string, names []string) []string {
+		return slices.DeleteFunc(names, func(name string) bool {
+			_, ok := managed[kind+"/"+name]
+			return ok
+		})
+	}
+
+	for _, name := range names {
+		err := updateDeployment(m, name, func(deployment *appsv1.Deployment) error {
+			secrets, configMaps := PodReferences(&deployment.Spec.Template.Spec)
+			secrets = unmanaged("Secret", secrets)
+			configMaps = unmanaged("ConfigMap", configMaps)
+
+			checksum, err := configChecksum(ctx, c, guac.Namespace, secrets, configMaps)
+			if err != nil {
+				return err
+			}
+
+			setPodAnnotation(&deployment.Spec.Template, configChecksumAnnotation, checksum)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// configChecksum returns a checksum over the data of Secrets and ConfigMaps.
+// Objects which do not exist (yet) are part of the checksum by name only,
+// so that their creation changes the checksum. The checksum is empty if
+// no objects are given.
+func configChecksum(ctx context.Context, c client.Client, namespace string, secrets, configMaps []string) (string, error) {
+	if len(secrets) == 0 && len(configMaps) == 0 {
+		return "", nil
+	}
+
+	h := sha256.New()
+
+	for _, name := range secrets {
+		var secret corev1.Secret
+		if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &secret); client.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+
+		writeChecksumEntry(h, "Secret/"+name)
+		writeChecksumData(h, secret.Data)
+	}
+
+	for _, name := range configMaps {
+		var configMap corev1.ConfigMap
+		if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, &configMap); client.IgnoreNotFound(err) != nil {
+			return "", err
+		}
+
+		writeChecksumEntry(h, "ConfigMap/"+name)
+		for _, k := range slices.Sorted(maps.Keys(configMap.Data)) {
+			writeChecksumEntry(h, k)
+			writeChecksumEntry(h, configMap.Data[k])
+		}
+		writeChecksumData(h, configMap.BinaryData)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeChecksumData(h hash.Hash, data map[string][]byte) {
+	for _, k := range slices.Sorted(maps.Keys(data)) {
+		writeChecksumEntry(h, k)
+		h.Write(data[k])
+		h.Write([]byte{0})
+	}
+}
+
+func writeChecksumEntry(h hash.Hash, s string) {
+	h.Write([]byte(s))
+	h.Write([]byte{0})
+}
+
+// PodReferences returns the sorted names of the Secrets and ConfigMaps
+// referenced by environment variables and volumes of a pod.
+func PodReferences(spec *corev1.PodSpec) (secrets, configMaps []string) {
+	secretSet := map[string]struct{}{}
+	configMapSet := map[string]struct{}{}
+
+	addSecret := func(name string) {
+		if name != "" {
+			secretSet[name] = struct{}{}
+		}
+	}
+
+	addConfigMap := func(name string) {
+		if name != "" {
+			configMapSet[name] = struct{}{}
+		}
+	}
+
+	containers := slices.Concat(spec.InitContainers, spec.Containers)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+
+			if env.ValueFrom.SecretKeyRef != nil {
+				addSecret(env.ValueFrom.SecretKeyRef.Name)
+			}
+
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				addConfigMap(env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+		}
+
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				addSecret(envFrom.SecretRef.Name)
+			}
+
+			if envFrom.ConfigMapRef != nil {
+				addConfigMap(envFrom.ConfigMapRef.Name)
+			}
+		}
+	}
+
+	for _, volume := range spec.Volumes {
+		if volume.Secret != nil {
+			addSecret(volume.Secret.SecretName)
+		}
+
+		if volume.ConfigMap != nil {
+			addConfigMap(volume.ConfigMap.Name)
+		}
+
+		if volume.Projected == nil {
+			continue
+		}
+
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil {
+				addSecret(source.Secret.Name)
+			}
+
+			if source.ConfigMap != nil {
+				addConfigMap(source.ConfigMap.Name)
+			}
+		}
+	}
+
+	return slices.Sorted(maps.Keys(secretSet)), slices.Sorted(maps.Keys(configMapSet))
+}
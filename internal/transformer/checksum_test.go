@@ -0,0 +1,132 @@
+package transformer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodReferences(t *testing.T) {
+	spec := &corev1.PodSpec{
+		InitContainers: []corev1.Container{{
+			Env: []corev1.EnvVar{{
+				Name: "POSTGRESQL_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "postgres"},
+						Key:                  "password",
+					},
+				},
+			}},
+		}},
+		Containers: []corev1.Container{{
+			Env: []corev1.EnvVar{{
+				Name: "POSTGRESQL_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "postgres"},
+						Key:                  "password",
+					},
+				},
+			}},
+			EnvFrom: []corev1.EnvFromSource{{
+				ConfigMapRef: &corev1.ConfigMapEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+				},
+			}},
+		}},
+		Volumes: []corev1.Volume{
+			{
+				Name: "ca-bundle",
+				VolumeSource: corev1.VolumeSource{
+					Projected: &corev1.ProjectedVolumeSource{
+						Sources: []corev1.VolumeProjection{
+							{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "ca"}}},
+						},
+					},
+				},
+			},
+			{
+				Name: "branding",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "branding"},
+					},
+				},
+			},
+		},
+	}
+
+	secrets, configMaps := PodReferences(spec)
+
+	if want := []string{"ca", "postgres"}; !cmp.Equal(want, secrets) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, secrets))
+	}
+
+	if want := []string{"branding", "settings"}; !cmp.Equal(want, configMaps) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, configMaps))
+	}
+}
+
+func TestConfigChecksum(t *testing.T) {
+	ctx := context.Background()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("initial")},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	initial, err := configChecksum(ctx, c, "default", []string{"postgres"}, []string{"settings"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if initial == "" {
+		t.Fatal("expected checksum")
+	}
+
+	secret.Data["password"] = []byte("rotated")
+	if err := c.Update(ctx, secret); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := configChecksum(ctx, c, "default", []string{"postgres"}, []string{"settings"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rotated == initial {
+		t.Error("expected checksum to change after rotation")
+	}
+
+	// Creating a missing config map changes the checksum as well.
+	if err := c.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"},
+		Data:       map[string]string{"key": "value"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := configChecksum(ctx, c, "default", []string{"postgres"}, []string{"settings"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created == rotated {
+		t.Error("expected checksum to change after creation")
+	}
+
+	empty, err := configChecksum(ctx, c, "default", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if empty != "" {
+		t.Errorf("expected empty checksum, got %q", empty)
+	}
+}
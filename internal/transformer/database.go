@@ -0,0 +1,176 @@
+package transformer
+
+import "github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+
+const (
+	postgresImage = "docker.io/library/postgres:17.6-alpine"
+	mysqlImage    = "docker.io/library/mysql:8.4.6"
+	// Microsoft publishes no versioned image of the command line tools,
+	// the server image ships mssql-tools18 since 2022 CU14.
+	sqlServerImage = "mcr.microsoft.com/mssql/server:2022-CU14-ubuntu-22.04"
+)
+
+// database describes a database backend of the JDBC authentication.
+type database struct {
+	// Name of the database as used by `initdb.sh`, the schema
+	// directories of the Guacamole image and as authentication source.
+	name string
+
+	// Image providing the database client.
+	image string
+
+	// Connection parameters.
+	params []v1alpha1.Parameter
+
+	// Script waiting for the database and loading `/data/initdb.sql`
+	// into an empty database.
+	loadScript string
+
+	// Script applying the upgrade scripts in `/data` in order.
+	upgradeScript string
+}
+
+// databaseFor returns the configured database backend or nil
+// if no database is configured.
+func databaseFor(auth *v1alpha1.Auth) *database {
+	switch {
+	case auth.Postgres != nil:
+		return &database{
+			name:          "postgresql",
+			image:         postgresImage,
+			params:        auth.Postgres.Parameter,
+			loadScript:    postgresLoadScript,
+			upgradeScript: postgresUpgradeScript,
+		}
+	case auth.MySQL != nil:
+		return &database{
+			name:          "mysql",
+			image:         mysqlImage,
+			params:        auth.MySQL.Parameter,
+			loadScript:    mysqlLoadScript,
+			upgradeScript: mysqlUpgradeScript,
+		}
+	case auth.SQLServer != nil:
+		return &database{
+			name:          "sqlserver",
+			image:         sqlServerImage,
+			params:        auth.SQLServer.Parameter,
+			loadScript:    sqlServerLoadScript,
+			upgradeScript: sqlServerUpgradeScript,
+		}
+	}
+
+	return nil
+}
+
+// Selects the upgrade scripts of DATABASE between FROM_VERSION (exclusive)
+// and TO_VERSION (inclusive). Scripts are prefixed with their position
+// to be applied in order.
+const selectUpgradeScripts = `set -e
+version_le() {
+    [ "$(printf '%s\n%s\n' "$1" "$2" | sort -V | head -n 1)" = "$1" ]
+}
+i=0
+for FILE in $(find /opt/guacamole -path "*/$DATABASE/schema/upgrade/upgrade-pre-*.sql" | sort -V); do
+    VERSION=${FILE##*/upgrade-pre-}
+    VERSION=${VERSION%.sql}
+    if ! version_le "$VERSION" "$FROM_VERSION" && version_le "$VERSION" "$TO_VERSION"; then
+        i=$((i + 1))
+        echo "Selecting $FILE."
+        cp "$FILE" "/data/$(printf '%03d' $i)-${FILE##*/}"
+    fi
+done`
+
+const postgresLoadScript = `export PGPASSWORD=$POSTGRESQL_PASSWORD
+PSQL="psql -h $POSTGRESQL_HOSTNAME -d $POSTGRESQL_DATABASE -U $POSTGRESQL_USER -p $POSTGRESQL_PORT -w"
+MAX_RETRIES=20
+i=1
+while [ "$i" -le $MAX_RETRIES ]
+do
+    if pg_isready -h $POSTGRESQL_HOSTNAME -d $POSTGRESQL_DATABASE -U $POSTGRESQL_USER -p $POSTGRESQL_PORT; then
+        echo "Database is ready to accept connections."
+        # Only initialize empty databases, existing schemas are upgraded by the operator.
+        if [ -n "$($PSQL -tAc "SELECT to_regclass('guacamole_user')")" ]; then
+            echo "Database schema exists."
+            exit 0
+        fi
+        exec $PSQL -a -v ON_ERROR_STOP=1 -1 -f /data/initdb.sql
+    fi
+    echo "Waiting for PG database."
+    sleep 5
+    i=$((i + 1))
+done
+exit 1`
+
+// Applies the upgrade scripts, each in a single transaction.
+const postgresUpgradeScript = `set -e
+export PGPASSWORD=$POSTGRESQL_PASSWORD
+for FILE in /data/*.sql; do
+    [ -e "$FILE" ] || continue
+    echo "Applying $FILE."
+    psql -h $POSTGRESQL_HOSTNAME -d $POSTGRESQL_DATABASE -U $POSTGRESQL_USER -p $POSTGRESQL_PORT -w -a -v ON_ERROR_STOP=1 -1 -f "$FILE"
+done`
+
+// Guacamole accepts MYSQL_USER as a deprecated alias of MYSQL_USERNAME.
+const mysqlLoadScript = `export MYSQL_PWD=$MYSQL_PASSWORD
+MYSQL_USERNAME=${MYSQL_USERNAME:-$MYSQL_USER}
+MYSQL="mysql -h $MYSQL_HOSTNAME -P ${MYSQL_PORT:-3306} -u $MYSQL_USERNAME $MYSQL_DATABASE"
+MAX_RETRIES=20
+i=1
+while [ "$i" -le $MAX_RETRIES ]
+do
+    if mysqladmin ping -h $MYSQL_HOSTNAME -P ${MYSQL_PORT:-3306} -u $MYSQL_USERNAME --silent; then
+        echo "Database is ready to accept connections."
+        # Only initialize empty databases, existing schemas are upgraded by the operator.
+        if [ "$($MYSQL -N -B -e "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'guacamole_user'")" != "0" ]; then
+            echo "Database schema exists."
+            exit 0
+        fi
+        exec $MYSQL -v < /data/initdb.sql
+    fi
+    echo "Waiting for MySQL database."
+    sleep 5
+    i=$((i + 1))
+done
+exit 1`
+
+const mysqlUpgradeScript = `set -e
+export MYSQL_PWD=$MYSQL_PASSWORD
+MYSQL_USERNAME=${MYSQL_USERNAME:-$MYSQL_USER}
+for FILE in /data/*.sql; do
+    [ -e "$FILE" ] || continue
+    echo "Applying $FILE."
+    mysql -h $MYSQL_HOSTNAME -P ${MYSQL_PORT:-3306} -u $MYSQL_USERNAME -v $MYSQL_DATABASE < "$FILE"
+done`
+
+// sqlcmd of mssql-tools18 encrypts connections by default. The server
+// certificate is trusted (-C) as SQL Server uses a self-signed
+// certificate unless configured otherwise.
+const sqlServerLoadScript = `export SQLCMDPASSWORD=$SQLSERVER_PASSWORD
+SQLCMD="/opt/mssql-tools18/bin/sqlcmd -S $SQLSERVER_HOSTNAME,${SQLSERVER_PORT:-1433} -d $SQLSERVER_DATABASE -U $SQLSERVER_USERNAME -C -b"
+MAX_RETRIES=20
+i=1
+while [ "$i" -le $MAX_RETRIES ]
+do
+    if $SQLCMD -Q "SELECT 1" > /dev/null; then
+        echo "Database is ready to accept connections."
+        # Only initialize empty databases, existing schemas are upgraded by the operator.
+        if [ "$($SQLCMD -h -1 -W -Q "SET NOCOUNT ON; SELECT COUNT(*) FROM sys.tables WHERE name = 'guacamole_user'")" != "0" ]; then
+            echo "Database schema exists."
+            exit 0
+        fi
+        exec $SQLCMD -e -i /data/initdb.sql
+    fi
+    echo "Waiting for SQL Server database."
+    sleep 5
+    i=$((i + 1))
+done
+exit 1`
+
+const sqlServerUpgradeScript = `set -e
+export SQLCMDPASSWORD=$SQLSERVER_PASSWORD
+for FILE in /data/*.sql; do
+    [ -e "$FILE" ] || continue
+    echo "Applying $FILE."
+    /opt/mssql-tools18/bin/sqlcmd -S $SQLSERVER_HOSTNAME,${SQLSERVER_PORT:-1433} -d $SQLSERVER_DATABASE -U $SQLSERVER_USERNAME -C -b -e -i "$FILE"
+done`
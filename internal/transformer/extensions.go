@@ -0,0 +1,350 @@
+package transformer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+const (
+	extensionKeysVolumeName   = "extension-keys"
+	extensionKeysMountPath    = "/etc/extension-dl/keys"
+	extensionSourceVolumeName = "extension-source"
+	extensionSourceMountPath  = "/extension-sources"
+
+	extensionCredentialsVolumeName = "extension-credentials"
+	extensionCredentialsMountPath  = "/etc/extension-dl/credentials"
+	extensionCACertVolumeName      = "extension-ca"
+	extensionCACertMountPath       = "/etc/extension-dl/ca"
+	extensionCACertFile            = "ca.crt"
+
+	// Exit code of extension-dl if the verification of an extension failed.
+	extensionVerificationFailedExitCode = 3
+	// Prefix of the termination message of extension-dl if extensions were
+	// verified. Images without verification support exit without it.
+	extensionsVerifiedMessagePrefix = "Verified extensions:"
+)
+
+// extensionDownload defines an extension as expected by extension-dl.
+type extensionDownload struct {
+	URI    string             `json:"uri"`
+	SHA256 string             `json:"sha256,omitempty"`
+	Cosign *extensionDownload `json:"cosign,omitempty"`
+	GPG    *extensionDownload `json:"gpg,omitempty"`
+	Key    string             `json:"key,omitempty"`
+
+	Credentials string `json:"credentials,omitempty"`
+	CACert      string `json:"caCert,omitempty"`
+}
+
+// configureExtensionDownload configures the download of extensions. Extensions
+// to verify or to download with credentials are passed via environment, all
+// others as arguments to keep existing deployments unchanged. Public keys are
+// mounted from ConfigMaps, credentials and CA certificates from Secrets.
+func configureExtensionDownload(extensions []v1alpha1.Extension, container *corev1.Container, deployment *appsv1.Deployment) error {
+	sources := make([]string, 0, len(extensions))
+	for i, e := range extensions {
+		sources = append(sources, extensionSource(i, e, container, deployment))
+	}
+
+	if !slices.ContainsFunc(extensions, func(e v1alpha1.Extension) bool { return e.Verified() || e.Authenticated() }) {
+		container.Args = append(container.Args, sources...)
+		return nil
+	}
+
+	var projections []corev1.VolumeProjection
+
+	key := func(ref corev1.ConfigMapKeySelector) string {
+		file := fmt.Sprintf("%03d", len(projections))
+		projections = append(projections, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: ref.LocalObjectReference,
+				Items:                []corev1.KeyToPath{{Key: ref.Key, Path: file}},
+			},
+		})
+
+		return extensionKeysMountPath + "/" + file
+	}
+
+	downloads := make([]extensionDownload, 0, len(extensions))
+
+	for i, e := range extensions {
+		download := extensionDownload{URI: sources[i], SHA256: e.SHA256}
+		download.Credentials, download.CACert = extensionCredentials(i, e, container, deployment)
+
+		if e.Signature != nil && e.Signature.Cosign != nil {
+			uri, err := signatureURI(i, e, e.Signature.Cosign.URI, ".sig")
+			if err != nil {
+				return err
+			}
+
+			download.Cosign = &extensionDownload{URI: uri, Key: key(e.Signature.Cosign.PublicKeyRef)}
+		}
+
+		if e.Signature != nil && e.Signature.GPG != nil {
+			uri, err := signatureURI(i, e, e.Signature.GPG.URI, ".asc")
+			if err != nil {
+				return err
+			}
+
+			download.GPG = &extensionDownload{URI: uri, Key: key(e.Signature.GPG.PublicKeyRef)}
+		}
+
+		downloads = append(downloads, download)
+	}
+
+	value, err := json.Marshal(downloads)
+	if err != nil {
+		return err
+	}
+
+	container.Env = append(container.Env, corev1.EnvVar{Name: "EXTENSIONS", Value: string(value)})
+
+	if len(projections) == 0 {
+		return nil
+	}
+
+	ensureVolume(deployment, corev1.Volume{
+		Name: extensionKeysVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: projections,
+			},
+		},
+	})
+
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      extensionKeysVolumeName,
+		ReadOnly:  true,
+		MountPath: extensionKeysMountPath,
+	})
+
+	return nil
+}
+
+// signatureURI returns the URI of the signature of an extension. It defaults
+// to the URI of the extension with the given suffix. Extensions from local
+// sources have no such location and require an explicit URI.
+func signatureURI(i int, e v1alpha1.Extension, uri, suffix string) (string, error) {
+	if uri != "" {
+		return uri, nil
+	}
+
+	if e.URI == "" {
+		return "", fmt.Errorf("signature of extension %d requires a uri, only extensions downloaded from a URI have a default", i)
+	}
+
+	return e.URI + suffix, nil
+}
+
+// extensionSource returns the source of an extension as passed to
+// extension-dl. Local sources are mounted into the container.
+func extensionSource(i int, e v1alpha1.Extension, container *corev1.Container, deployment *appsv1.Deployment) string {
+	if e.URI != "" {
+		return e.URI
+	}
+
+	volume := corev1.Volume{Name: fmt.Sprintf("%s-%d", extensionSourceVolumeName, i)}
+	mountPath := fmt.Sprintf("%s/%d", extensionSourceMountPath, i)
+	source := mountPath
+
+	switch {
+	case e.Image != nil:
+		volume.Image = &corev1.ImageVolumeSource{
+			Reference:  e.Image.Reference,
+			PullPolicy: e.Image.PullPolicy,
+		}
+		source = path.Join(mountPath, e.Image.Path)
+	case e.ConfigMapKeyRef != nil:
+		volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: e.ConfigMapKeyRef.LocalObjectReference,
+			Items:                []corev1.KeyToPath{{Key: e.ConfigMapKeyRef.Key, Path: e.ConfigMapKeyRef.Key}},
+		}
+		source = path.Join(mountPath, e.ConfigMapKeyRef.Key)
+	case e.SecretKeyRef != nil:
+		volume.Secret = &corev1.SecretVolumeSource{
+			SecretName: e.SecretKeyRef.Name,
+			Items:      []corev1.KeyToPath{{Key: e.SecretKeyRef.Key, Path: e.SecretKeyRef.Key}},
+		}
+		source = path.Join(mountPath, e.SecretKeyRef.Key)
+	case e.PersistentVolumeClaim != nil:
+		volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: e.PersistentVolumeClaim.ClaimName,
+			ReadOnly:  true,
+		}
+		source = path.Join(mountPath, e.PersistentVolumeClaim.Path)
+	}
+
+	ensureVolume(deployment, volume)
+
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      volume.Name,
+		ReadOnly:  true,
+		MountPath: mountPath,
+	})
+
+	return source
+}
+
+// extensionCredentials mounts the credentials and CA certificates for the
+// download of an extension and returns their paths.
+func extensionCredentials(i int, e v1alpha1.Extension, container *corev1.Container, deployment *appsv1.Deployment) (credentials, caCert string) {
+	if e.CredentialsSecretRef != nil {
+		name := fmt.Sprintf("%s-%d", extensionCredentialsVolumeName, i)
+		credentials = fmt.Sprintf("%s/%d", extensionCredentialsMountPath, i)
+
+		ensureVolume(deployment, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: e.CredentialsSecretRef.Name,
+				},
+			},
+		})
+
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      name,
+			ReadOnly:  true,
+			MountPath: credentials,
+		})
+	}
+
+	if e.CACertSecretRef != nil {
+		name := fmt.Sprintf("%s-%d", extensionCACertVolumeName, i)
+		mountPath := fmt.Sprintf("%s/%d", extensionCACertMountPath, i)
+		caCert = mountPath + "/" + extensionCACertFile
+
+		ensureVolume(deployment, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: e.CACertSecretRef.Name,
+					Items:      []corev1.KeyToPath{{Key: e.CACertSecretRef.Key, Path: extensionCACertFile}},
+				},
+			},
+		})
+
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      name,
+			ReadOnly:  true,
+			MountPath: mountPath,
+		})
+	}
+
+	return credentials, caCert
+}
+
+// applyExtensionVerificationStatus reports the verification of extensions
+// in the status. Failures are read from the termination message of the
+// extension-dl init container in the pods of the instance.
+func applyExtensionVerificationStatus(ctx context.Context, c client.Client, guac *v1alpha1.Guacamole) error {
+	if !slices.ContainsFunc(guac.Spec.Extensions, func(e v1alpha1.Extension) bool { return e.Verified() }) {
+		meta.RemoveStatusCondition(&guac.Status.Conditions, string(v1alpha1.GuacamoleExtensionsVerified))
+		return nil
+	}
+
+	pods, err := deploymentPods(ctx, c, guac.Namespace, GuacamoleDeploymentName+"-"+guac.Name)
+	if err != nil {
+		return err
+	}
+
+	verified := false
+
+	for _, pod := range pods {
+		for _, status := range pod.Status.InitContainerStatuses {
+			if status.Name != "extension-dl" {
+				continue
+			}
+
+			for _, terminated := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+				if terminated == nil {
+					continue
+				}
+
+				if terminated.ExitCode == extensionVerificationFailedExitCode {
+					guac.Status.MarkExtensionVerificationFailed(terminated.Message)
+					return nil
+				}
+			}
+
+			if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode == 0 {
+				// Fail closed if the downloader did not confirm the verification.
+				if !strings.HasPrefix(terminated.Message, extensionsVerifiedMessagePrefix) {
+					guac.Status.MarkExtensionVerificationFailed("Extension downloader did not report verified extensions, " +
+						"the image " + status.Image + " may not support verification.")
+					return nil
+				}
+
+				verified = true
+			}
+		}
+	}
+
+	if verified {
+		guac.Status.MarkExtensionsVerified()
+	} else {
+		guac.Status.MarkExtensionsVerificationPending()
+	}
+
+	return nil
+}
+
+// deploymentPods returns the pods of the Guacamole deployment, found via
+// the owner references of its replica sets.
+func deploymentPods(ctx context.Context, c client.Client, namespace, name string) ([]corev1.Pod, error) {
+	// Pods and replica sets inherit the labels of the pod template.
+	selector := client.MatchingLabels{nameLabel: GuacamoleDeploymentName}
+
+	var replicaSets appsv1.ReplicaSetList
+	if err := c.List(ctx, &replicaSets, client.InNamespace(namespace), selector); err != nil {
+		return nil, fmt.Errorf("error listing replica sets: %w", err)
+	}
+
+	owners := map[string]struct{}{}
+
+	for _, rs := range replicaSets.Items {
+		if ownedBy(rs.OwnerReferences, "Deployment", name) {
+			owners[rs.Name] = struct{}{}
+		}
+	}
+
+	if len(owners) == 0 {
+		return nil, nil
+	}
+
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(namespace), selector); err != nil {
+		return nil, fmt.Errorf("error listing pods: %w", err)
+	}
+
+	var owned []corev1.Pod
+
+	for _, pod := range pods.Items {
+		for owner := range owners {
+			if ownedBy(pod.OwnerReferences, "ReplicaSet", owner) {
+				owned = append(owned, pod)
+				break
+			}
+		}
+	}
+
+	return owned, nil
+}
+
+// ownedBy returns whether owner references contain an owner.
+func ownedBy(refs []metav1.OwnerReference, kind, name string) bool {
+	return slices.ContainsFunc(refs, func(ref metav1.OwnerReference) bool {
+		return ref.Kind == kind && ref.Name == name
+	})
+}
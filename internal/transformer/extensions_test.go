@@ -0,0 +1,377 @@
+package transformer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+func TestConfigureExtensionDownload(t *testing.T) {
+	const uri = "https://example.com/guacamole-auth-quickconnect-1.6.0.jar"
+
+	// Extensions without verification are passed as arguments.
+	var container corev1.Container
+	if err := configureExtensionDownload([]v1alpha1.Extension{{URI: uri}}, &container, &appsv1.Deployment{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{uri}; !cmp.Equal(want, container.Args) || container.Env != nil {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, container.Args))
+	}
+
+	extensions := []v1alpha1.Extension{
+		{URI: uri, SHA256: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+		{
+			URI: "https://example.com/branding.jar",
+			Signature: &v1alpha1.ExtensionSignature{
+				GPG: &v1alpha1.GPGSignature{
+					PublicKeyRef: corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "keys"},
+						Key:                  "branding.asc",
+					},
+				},
+			},
+		},
+	}
+
+	container = corev1.Container{}
+	deployment := &appsv1.Deployment{}
+
+	if err := configureExtensionDownload(extensions, &container, deployment); err != nil {
+		t.Fatal(err)
+	}
+
+	wantEnv := []corev1.EnvVar{{
+		Name: "EXTENSIONS",
+		Value: `[{"uri":"` + uri + `","sha256":"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},` +
+			`{"uri":"https://example.com/branding.jar","gpg":{"uri":"https://example.com/branding.jar.asc","key":"/etc/extension-dl/keys/000"}}]`,
+	}}
+
+	if !cmp.Equal(wantEnv, container.Env) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantEnv, container.Env))
+	}
+
+	if container.Args != nil {
+		t.Errorf("expected no arguments, got %v", container.Args)
+	}
+
+	wantVolumes := []corev1.Volume{{
+		Name: extensionKeysVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{
+					ConfigMap: &corev1.ConfigMapProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "keys"},
+						Items:                []corev1.KeyToPath{{Key: "branding.asc", Path: "000"}},
+					},
+				}},
+			},
+		},
+	}}
+
+	if got := deployment.Spec.Template.Spec.Volumes; !cmp.Equal(wantVolumes, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantVolumes, got))
+	}
+}
+
+func TestConfigureExtensionDownloadSignatureURI(t *testing.T) {
+	publicKeyRef := corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "keys"},
+		Key:                  "cosign.pub",
+	}
+
+	tests := []struct {
+		name      string
+		extension v1alpha1.Extension
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "default for uri",
+			extension: v1alpha1.Extension{
+				URI:       "https://example.com/branding.jar",
+				Signature: &v1alpha1.ExtensionSignature{Cosign: &v1alpha1.CosignSignature{PublicKeyRef: publicKeyRef}},
+			},
+			want: "https://example.com/branding.jar.sig",
+		},
+		{
+			name: "explicit for image",
+			extension: v1alpha1.Extension{
+				Image: &v1alpha1.ImageExtensionSource{Reference: "registry.example.com/branding:1.0.0", Path: "branding.jar"},
+				Signature: &v1alpha1.ExtensionSignature{Cosign: &v1alpha1.CosignSignature{
+					URI:          "https://example.com/branding.jar.sig",
+					PublicKeyRef: publicKeyRef,
+				}},
+			},
+			want: "https://example.com/branding.jar.sig",
+		},
+		{
+			name: "missing for image",
+			extension: v1alpha1.Extension{
+				Image:     &v1alpha1.ImageExtensionSource{Reference: "registry.example.com/branding:1.0.0"},
+				Signature: &v1alpha1.ExtensionSignature{Cosign: &v1alpha1.CosignSignature{PublicKeyRef: publicKeyRef}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing for ConfigMap",
+			extension: v1alpha1.Extension{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "extensions"},
+					Key:                  "branding.jar",
+				},
+				Signature: &v1alpha1.ExtensionSignature{GPG: &v1alpha1.GPGSignature{PublicKeyRef: publicKeyRef}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing for PersistentVolumeClaim",
+			extension: v1alpha1.Extension{
+				PersistentVolumeClaim: &v1alpha1.PersistentVolumeClaimExtensionSource{ClaimName: "extensions"},
+				Signature:             &v1alpha1.ExtensionSignature{GPG: &v1alpha1.GPGSignature{PublicKeyRef: publicKeyRef}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var container corev1.Container
+			err := configureExtensionDownload([]v1alpha1.Extension{tt.extension}, &container, &appsv1.Deployment{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var downloads []extensionDownload
+			if err := json.Unmarshal([]byte(container.Env[0].Value), &downloads); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := downloads[0].Cosign.URI; got != tt.want {
+				t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestExtensionSource(t *testing.T) {
+	extensions := []v1alpha1.Extension{
+		{Image: &v1alpha1.ImageExtensionSource{Reference: "registry.example.com/extensions/sso:1.6.0", Path: "/extensions"}},
+		{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "extensions"},
+				Key:                  "branding.jar",
+			},
+		},
+		{PersistentVolumeClaim: &v1alpha1.PersistentVolumeClaimExtensionSource{ClaimName: "extensions"}},
+	}
+
+	var container corev1.Container
+	deployment := &appsv1.Deployment{}
+
+	if err := configureExtensionDownload(extensions, &container, deployment); err != nil {
+		t.Fatal(err)
+	}
+
+	wantArgs := []string{
+		"/extension-sources/0/extensions",
+		"/extension-sources/1/branding.jar",
+		"/extension-sources/2",
+	}
+
+	if !cmp.Equal(wantArgs, container.Args) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantArgs, container.Args))
+	}
+
+	wantVolumes := []corev1.Volume{
+		{
+			Name: "extension-source-0",
+			VolumeSource: corev1.VolumeSource{
+				Image: &corev1.ImageVolumeSource{Reference: "registry.example.com/extensions/sso:1.6.0"},
+			},
+		},
+		{
+			Name: "extension-source-1",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "extensions"},
+					Items:                []corev1.KeyToPath{{Key: "branding.jar", Path: "branding.jar"}},
+				},
+			},
+		},
+		{
+			Name: "extension-source-2",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "extensions", ReadOnly: true},
+			},
+		},
+	}
+
+	if got := deployment.Spec.Template.Spec.Volumes; !cmp.Equal(wantVolumes, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantVolumes, got))
+	}
+
+	if got := len(container.VolumeMounts); got != len(extensions) {
+		t.Errorf("expected %d volume mounts, got %d", len(extensions), got)
+	}
+}
+
+func TestExtensionCredentials(t *testing.T) {
+	extensions := []v1alpha1.Extension{
+		{URI: "https://example.com/guacamole-auth-quickconnect-1.6.0.jar"},
+		{
+			URI:                  "https://artifacts.example.com/guacamole-auth-custom.jar",
+			CredentialsSecretRef: &corev1.LocalObjectReference{Name: "artifacts"},
+			CACertSecretRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "artifacts-ca"},
+				Key:                  "ca.pem",
+			},
+		},
+	}
+
+	var container corev1.Container
+	deployment := &appsv1.Deployment{}
+
+	if err := configureExtensionDownload(extensions, &container, deployment); err != nil {
+		t.Fatal(err)
+	}
+
+	wantEnv := []corev1.EnvVar{{
+		Name: "EXTENSIONS",
+		Value: `[{"uri":"https://example.com/guacamole-auth-quickconnect-1.6.0.jar"},` +
+			`{"uri":"https://artifacts.example.com/guacamole-auth-custom.jar",` +
+			`"credentials":"/etc/extension-dl/credentials/1","caCert":"/etc/extension-dl/ca/1/ca.crt"}]`,
+	}}
+
+	if !cmp.Equal(wantEnv, container.Env) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantEnv, container.Env))
+	}
+
+	wantVolumes := []corev1.Volume{
+		{
+			Name: "extension-credentials-1",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: "artifacts"},
+			},
+		},
+		{
+			Name: "extension-ca-1",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: "artifacts-ca",
+					Items:      []corev1.KeyToPath{{Key: "ca.pem", Path: "ca.crt"}},
+				},
+			},
+		},
+	}
+
+	if got := deployment.Spec.Template.Spec.Volumes; !cmp.Equal(wantVolumes, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantVolumes, got))
+	}
+}
+
+func TestApplyExtensionVerificationStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status corev1.ContainerStatus
+		want   metav1.ConditionStatus
+		reason v1alpha1.GuacamoleConditionReason
+	}{
+		{
+			name: "verified",
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Message: "Verified extensions: https://example.com/branding.jar",
+			}}},
+			want:   metav1.ConditionTrue,
+			reason: v1alpha1.GuacamoleExtensionsVerifiedReason,
+		},
+		{
+			name: "failed",
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: extensionVerificationFailedExitCode,
+				Message:  "checksum mismatch",
+			}}},
+			want:   metav1.ConditionFalse,
+			reason: v1alpha1.GuacamoleExtensionVerificationFailed,
+		},
+		{
+			// Downloader images without verification support ignore the
+			// extensions and exit successfully.
+			name: "unsupported image",
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Message: "",
+			}}},
+			want:   metav1.ConditionFalse,
+			reason: v1alpha1.GuacamoleExtensionVerificationFailed,
+		},
+		{
+			name:   "running",
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			want:   metav1.ConditionUnknown,
+			reason: v1alpha1.GuacamoleExtensionsVerificationPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels := map[string]string{nameLabel: GuacamoleDeploymentName}
+
+			replicaSet := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+				Name:            "guacamole-example-1",
+				Namespace:       "default",
+				Labels:          labels,
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: "guacamole-example"}},
+			}}
+
+			status := tt.status
+			status.Name = "extension-dl"
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "guacamole-example-1-abcde",
+					Namespace:       "default",
+					Labels:          labels,
+					OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: replicaSet.Name}},
+				},
+				Status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{status}},
+			}
+
+			c := fake.NewClientBuilder().WithObjects(replicaSet, pod).Build()
+
+			guac := &v1alpha1.Guacamole{
+				ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+				Spec: v1alpha1.GuacamoleSpec{
+					Extensions: []v1alpha1.Extension{{URI: "https://example.com/branding.jar", SHA256: "abc"}},
+				},
+			}
+
+			if err := applyExtensionVerificationStatus(context.Background(), c, guac); err != nil {
+				t.Fatal(err)
+			}
+
+			condition := meta.FindStatusCondition(guac.Status.Conditions, string(v1alpha1.GuacamoleExtensionsVerified))
+			if condition == nil {
+				t.Fatal("expected extensions condition")
+			}
+
+			if condition.Status != tt.want || condition.Reason != string(tt.reason) {
+				t.Errorf("unexpected condition %s/%s, want %s/%s", condition.Status, condition.Reason, tt.want, tt.reason)
+			}
+		})
+	}
+}
@@ -19,24 +19,43 @@ import (
 
 const (
 	GuacamoleDeploymentName = "guacamole"
-	extensionDLImage        = "ghcr.io/guacamole-operator/extension-dl:07695df"
+	extensionDLImage        = "ghcr.io/guacamole-operator/extension-dl:1.0.0"
 	initDBVolumeName        = "initdb"
 	extensionsVolumeName    = "extensions"
 )
 
 // Guacamole transform the guacamole deployment manifest.
 func Guacamole(client client.Client) declarative.ObjectTransform {
-	return func(_ context.Context, obj declarative.DeclarativeObject, m *manifest.Objects) error {
+	return func(ctx context.Context, obj declarative.DeclarativeObject, m *manifest.Objects) error {
 		guac := obj.(*v1alpha1.Guacamole)
 
-		if guac.Spec.TLS != nil {
-			if err := applyTLSConfiguration(guac.Spec.TLS, m); err != nil {
+		// The catalog is part of the package only and never applied.
+		catalog, err := extractExtensionCatalog(m)
+		if err != nil {
+			return err
+		}
+
+		extensions, extensionProperties, err := resolveExtensions(catalog, guac.Spec.Extensions)
+		if err != nil {
+			return err
+		}
+
+		if err := applyContainerImage(GuacamoleDeploymentName, "guacamole", images(guac).Guacamole, m); err != nil {
+			return err
+		}
+
+		if err := applyTLSConfiguration(guac, m); err != nil {
+			return err
+		}
+
+		if db := databaseFor(&guac.Spec.Auth); db != nil {
+			db.image = imageOrDefault(images(guac).Database, db.image)
+
+			if err := applyDatabaseConfiguration(db, m); err != nil {
 				return err
 			}
-		}
 
-		if guac.Spec.Auth.Postgres != nil {
-			if err := applyPostgresConfiguration(guac, m); err != nil {
+			if err := applySchemaUpgrade(ctx, client, guac, db, m); err != nil {
 				return err
 			}
 		}
@@ -47,18 +66,107 @@ func Guacamole(client client.Client) declarative.ObjectTransform {
 			}
 		}
 
+		if guac.Spec.Auth.LDAP != nil {
+			if err := applyLDAPConfiguration(guac, m); err != nil {
+				return err
+			}
+		}
+
+		if err := applyAuthConfiguration(guac, extensions, m); err != nil {
+			return err
+		}
+
+		if guac.Spec.Auth.JSON != nil {
+			if err := applyJSONAuthConfiguration(ctx, client, guac, m); err != nil {
+				return err
+			}
+		}
+
 		if guac.Spec.AdditionalSettings != nil {
 			if err := applyAdditionalSettings(guac.Spec.AdditionalSettings, m); err != nil {
 				return err
 			}
 		}
 
+		if len(guac.Spec.Properties) > 0 {
+			if err := applyPropertiesConfiguration(guac.Spec.Properties, m); err != nil {
+				return err
+			}
+		}
+
+		if guac.Spec.Logging != nil {
+			if err := applyLoggingConfiguration(guac.Spec.Logging, m); err != nil {
+				return err
+			}
+		}
+
 		if guac.Spec.Extensions != nil {
-			if err := applyExtensions(guac.Spec.Extensions, m); err != nil {
+			image := imageOrDefault(images(guac).ExtensionDownloader, extensionDLImage)
+			if err := applyExtensions(extensions, image, m); err != nil {
+				return err
+			}
+
+			if err := applyExtensionProperties(extensionProperties, m); err != nil {
 				return err
 			}
 		}
 
+		if err := applyExtensionVerificationStatus(ctx, client, guac); err != nil {
+			return err
+		}
+
+		if guac.Spec.Branding != nil {
+			if err := applyBranding(ctx, client, guac, m); err != nil {
+				return err
+			}
+		}
+
+		if guac.Spec.Recording != nil {
+			if err := applyRecordingConfiguration(guac, m); err != nil {
+				return err
+			}
+		}
+
+		if guac.Spec.Metrics != nil {
+			if err := applyGuacamoleMetrics(guac, m); err != nil {
+				return err
+			}
+
+			if guac.Spec.Metrics.ServiceMonitor != nil {
+				if err := applyServiceMonitor(guac, m); err != nil {
+					return err
+				}
+			}
+		}
+
+		if guac.Spec.NetworkPolicy != nil {
+			if err := applyNetworkPolicies(guac, m); err != nil {
+				return err
+			}
+		}
+
+		// Applied after all containers are in place so that overrides
+		// can target init containers as well.
+		if guac.Spec.Guacamole != nil {
+			if err := applyWebAppConfiguration(guac.Spec.Guacamole, m); err != nil {
+				return err
+			}
+		}
+
+		if err := applyAuthExtensionStatus(guac, m); err != nil {
+			return err
+		}
+
+		// Roll pods on changes of referenced Secrets and ConfigMaps,
+		// e.g. rotated passwords or renewed certificates.
+		if err := applyConfigChecksums(ctx, client, guac, m); err != nil {
+			return err
+		}
+
+		if err := applyPackageVersionLabel(guac, m); err != nil {
+			return err
+		}
+
 		// Add instance name to resources.
 		if err := addInstanceName(m, guac); err != nil {
 			return err
@@ -69,55 +177,77 @@ func Guacamole(client client.Client) declarative.ObjectTransform {
 	}
 }
 
-func applyTLSConfiguration(tls *v1alpha1.TLS, m *manifest.Objects) error {
-	if tls.CaCertificates == nil {
-		return nil
+// applyTLSConfiguration mounts trusted CA certificates and configures
+// TLS for connections to guacd.
+func applyTLSConfiguration(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	var sources []corev1.VolumeProjection
+
+	if guac.Spec.TLS != nil && guac.Spec.TLS.CaCertificates != nil {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: guac.Spec.TLS.CaCertificates.SecretRef,
+			},
+		})
 	}
 
-	for idx, item := range m.Items {
-		if isDeployment(item) && item.GetName() == GuacamoleDeploymentName {
-			var deployment appsv1.Deployment
-			err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredObject().Object, &deployment)
-			if err != nil {
-				return fmt.Errorf("error converting deployment from unstructured: %w", err)
-			}
+	var guacdTLS *v1alpha1.GuacdTLS
+	if guac.Spec.Guacd != nil {
+		guacdTLS = guac.Spec.Guacd.TLS
+	}
 
-			// Mount CAs.
-			ensureVolume(&deployment, corev1.Volume{
-				Name: "ca-bundle",
-				VolumeSource: corev1.VolumeSource{
-					Secret: &corev1.SecretVolumeSource{
-						SecretName: tls.CaCertificates.SecretRef.Name,
-					},
-				},
-			})
+	if guacdTLS != nil {
+		secretName := guacdTLS.SecretName(guac.Name)
 
-			ensureContainerVolumeMount(&deployment, "guacamole", corev1.VolumeMount{
-				Name:      "ca-bundle",
-				ReadOnly:  true,
-				MountPath: "/opt/ca-bundle",
-			})
+		// Trust the CA of the guacd certificate.
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: secretName,
+				},
+				Items: []corev1.KeyToPath{{
+					Key:  guacdCACertificateKey,
+					Path: guacdCACertificateBundleKey,
+				}},
+			},
+		})
+	}
 
-			u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment)
-			if err != nil {
-				return err
-			}
+	if len(sources) == 0 {
+		return nil
+	}
 
-			obj, err := manifest.NewObject(&unstructured.Unstructured{Object: u})
-			if err != nil {
-				return err
-			}
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		// Mount CAs.
+		ensureVolume(deployment, corev1.Volume{
+			Name: "ca-bundle",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: sources,
+				},
+			},
+		})
 
-			m.Items[idx] = obj
+		ensureContainerVolumeMount(deployment, "guacamole", corev1.VolumeMount{
+			Name:      "ca-bundle",
+			ReadOnly:  true,
+			MountPath: "/opt/ca-bundle",
+		})
 
-			break
+		if guacdTLS != nil {
+			deployment.Spec.Template.Spec.Containers[0].Env = ensureEnvVar(
+				deployment.Spec.Template.Spec.Containers[0].Env,
+				corev1.EnvVar{
+					Name:  "GUACD_SSL",
+					Value: "true",
+				},
+			)
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
-func applyPostgresConfiguration(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+func applyDatabaseConfiguration(db *database, m *manifest.Objects) error {
 	for idx, item := range m.Items {
 		if isDeployment(item) && item.GetName() == GuacamoleDeploymentName {
 			var deployment appsv1.Deployment
@@ -128,8 +258,8 @@ func applyPostgresConfiguration(guac *v1alpha1.Guacamole, m *manifest.Objects) e
 
 			// Image version overwritten via kustomize before this transformation runs.
 			guacImage := deployment.Spec.Template.Spec.Containers[0].Image
-			deployment.Spec.Template.Spec.InitContainers = postgresInitContainer(guacImage)
-			deployment.Spec.Template.Spec.InitContainers[1].Env = envVarFromParameters(guac.Spec.Auth.Postgres.Parameter)
+			deployment.Spec.Template.Spec.InitContainers = databaseInitContainers(db, guacImage)
+			deployment.Spec.Template.Spec.InitContainers[1].Env = envVarFromParameters(db.params)
 
 			deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, corev1.Volume{
 				Name: initDBVolumeName,
@@ -138,7 +268,7 @@ func applyPostgresConfiguration(guac *v1alpha1.Guacamole, m *manifest.Objects) e
 				},
 			})
 
-			envs := envVarFromParameters(guac.Spec.Auth.Postgres.Parameter)
+			envs := envVarFromParameters(db.params)
 			deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, envs...)
 
 			u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment)
@@ -160,7 +290,7 @@ func applyPostgresConfiguration(guac *v1alpha1.Guacamole, m *manifest.Objects) e
 	return nil
 }
 
-func postgresInitContainer(guacImage string) []corev1.Container {
+func databaseInitContainers(db *database, guacImage string) []corev1.Container {
 	createDB := corev1.Container{
 		Name:  "create-init-db",
 		Image: guacImage,
@@ -175,13 +305,13 @@ func postgresInitContainer(guacImage string) []corev1.Container {
 		},
 		Args: []string{
 			"-c",
-			"/opt/guacamole/bin/initdb.sh --postgresql > /data/initdb.sql",
+			"/opt/guacamole/bin/initdb.sh --" + db.name + " > /data/initdb.sql",
 		},
 	}
 
 	loadDB := corev1.Container{
 		Name:  "load-db",
-		Image: "docker.io/library/postgres:alpine",
+		Image: db.image,
 		VolumeMounts: []corev1.VolumeMount{
 			{
 				Name:      initDBVolumeName,
@@ -193,21 +323,7 @@ func postgresInitContainer(guacImage string) []corev1.Container {
 		},
 		Args: []string{
 			"-c",
-			`export PGPASSWORD=$POSTGRESQL_PASSWORD
-MAX_RETRIES=20
-i=1
-while [ "$i" -le $MAX_RETRIES ]
-do
-    if pg_isready -h $POSTGRESQL_HOSTNAME -d $POSTGRESQL_DATABASE -U $POSTGRESQL_USER -p $POSTGRESQL_PORT; then
-        echo "Database is ready to accept connections."
-        psql -h $POSTGRESQL_HOSTNAME -d $POSTGRESQL_DATABASE -U $POSTGRESQL_USER -p $POSTGRESQL_PORT -a -w -f /data/initdb.sql || true
-        exit 0
-    fi
-    echo "Waiting for PG database."
-    sleep 5
-    i=$((i + 1))
-done
-exit 1`,
+			db.loadScript,
 		},
 	}
 
@@ -245,6 +361,15 @@ func applyOIDCConfiguration(guac *v1alpha1.Guacamole, m *manifest.Objects) error
 	return nil
 }
 
+func applyLDAPConfiguration(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		envs := envVarFromParameters(guac.Spec.Auth.LDAP.Parameter)
+		deployment.Spec.Template.Spec.Containers[0].Env = append(deployment.Spec.Template.Spec.Containers[0].Env, envs...)
+
+		return nil
+	})
+}
+
 func applyAdditionalSettings(values map[string]string, m *manifest.Objects) error {
 	for idx, item := range m.Items {
 		if isDeployment(item) && item.GetName() == GuacamoleDeploymentName {
@@ -291,12 +416,7 @@ func applyAdditionalSettings(values map[string]string, m *manifest.Objects) erro
 	return nil
 }
 
-func applyExtensions(extensions []v1alpha1.Extension, m *manifest.Objects) error {
-	var extUris []string
-	for _, e := range extensions {
-		extUris = append(extUris, e.URI)
-	}
-
+func applyExtensions(extensions []v1alpha1.Extension, image string, m *manifest.Objects) error {
 	for idx, item := range m.Items {
 		if isDeployment(item) && item.GetName() == GuacamoleDeploymentName {
 			var deployment appsv1.Deployment
@@ -308,8 +428,13 @@ func applyExtensions(extensions []v1alpha1.Extension, m *manifest.Objects) error
 			// Apply init container for extension download.
 			downloaderContainer := corev1.Container{
 				Name:  "extension-dl",
-				Image: extensionDLImage,
-				Args:  extUris,
+				Image: image,
+				// Verification failures are reported via termination message.
+				TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			}
+
+			if err := configureExtensionDownload(extensions, &downloaderContainer, &deployment); err != nil {
+				return err
 			}
 
 			// Apply cluster proxy.
@@ -365,6 +490,39 @@ func applyExtensions(extensions []v1alpha1.Extension, m *manifest.Objects) error
 	return nil
 }
 
+func applyWebAppConfiguration(webApp *v1alpha1.WebApp, m *manifest.Objects) error {
+	if webApp.Metadata != nil {
+		if err := applyAnnotations(GuacamoleDeploymentName, webApp.Metadata, m); err != nil {
+			return err
+		}
+	}
+
+	if webApp.Template == nil {
+		return nil
+	}
+
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		applyPodTemplate(webApp.Template, &deployment.Spec.Template.Spec)
+		return nil
+	})
+}
+
+// applyPackageVersionLabel records the resolved package version on the
+// deployment. The deployed version is read from it if the status is lost.
+func applyPackageVersionLabel(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	if guac.Status.Version == "" {
+		return nil
+	}
+
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		deployment.Labels = mergeLabels(deployment.Labels, map[string]string{
+			v1alpha1.PackageVersionLabel: guac.Status.Version,
+		})
+
+		return nil
+	})
+}
+
 func addInstanceName(m *manifest.Objects, guac *v1alpha1.Guacamole) error {
 	for idx, item := range m.Items {
 		instance := item.GetName() + "-" + guac.Name
@@ -379,8 +537,15 @@ func addInstanceName(m *manifest.Objects, guac *v1alpha1.Guacamole) error {
 				return fmt.Errorf("error converting deployment from unstructured: %w", err)
 			}
 
+			// Pods are selected per instance, e.g. by NetworkPolicies. Not added
+			// to the immutable selector of the deployment.
+			deployment.Spec.Template.Labels = mergeLabels(deployment.Spec.Template.Labels, map[string]string{
+				instanceLabel: guac.Name,
+			})
+
+			// Service accounts are shared between deployments, e.g. by guacd pools.
 			if deployment.Spec.Template.Spec.ServiceAccountName != "" {
-				deployment.Spec.Template.Spec.ServiceAccountName = instance
+				deployment.Spec.Template.Spec.ServiceAccountName += "-" + guac.Name
 			}
 
 			if deployment.Name == GuacamoleDeploymentName+"-"+guac.Name {
@@ -417,8 +582,8 @@ func addAccessParameters(client client.Client, guac *v1alpha1.Guacamole) error {
 	ns := guac.Namespace
 
 	source := ""
-	if guac.Spec.Auth.Postgres != nil {
-		source = "postgresql"
+	if db := databaseFor(&guac.Spec.Auth); db != nil {
+		source = db.name
 	}
 
 	guac.Status.Access = &v1alpha1.Access{
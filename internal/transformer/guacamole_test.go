@@ -0,0 +1,115 @@
+package transformer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+func TestApplyLDAPConfiguration(t *testing.T) {
+	param := func(name, key string) v1alpha1.Parameter {
+		return v1alpha1.Parameter{
+			Name: name,
+			ValueFrom: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "ldap"},
+				Key:                  key,
+			},
+		}
+	}
+
+	env := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "ldap"},
+					Key:                  key,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		params []v1alpha1.Parameter
+		want   []corev1.EnvVar
+	}{
+		{
+			name: "required",
+			params: []v1alpha1.Parameter{
+				param("LDAP_HOSTNAME", "hostname"),
+				param("LDAP_USER_BASE_DN", "user-base-dn"),
+			},
+			want: []corev1.EnvVar{
+				{Name: "GUACAMOLE_HOME", Value: "/etc/guacamole"},
+				env("LDAP_HOSTNAME", "hostname"),
+				env("LDAP_USER_BASE_DN", "user-base-dn"),
+			},
+		},
+		{
+			name: "search bind",
+			params: []v1alpha1.Parameter{
+				param("LDAP_HOSTNAME", "hostname"),
+				param("LDAP_USER_BASE_DN", "user-base-dn"),
+				param("LDAP_SEARCH_BIND_DN", "bind-dn"),
+				param("LDAP_SEARCH_BIND_PASSWORD", "bind-password"),
+			},
+			want: []corev1.EnvVar{
+				{Name: "GUACAMOLE_HOME", Value: "/etc/guacamole"},
+				env("LDAP_HOSTNAME", "hostname"),
+				env("LDAP_USER_BASE_DN", "user-base-dn"),
+				env("LDAP_SEARCH_BIND_DN", "bind-dn"),
+				env("LDAP_SEARCH_BIND_PASSWORD", "bind-password"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := manifest.ParseObjects(context.Background(), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: guacamole
+spec:
+  template:
+    spec:
+      containers:
+        - name: guacamole
+          env:
+            - name: GUACAMOLE_HOME
+              value: /etc/guacamole
+`)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			guac := &v1alpha1.Guacamole{
+				Spec: v1alpha1.GuacamoleSpec{
+					Auth: v1alpha1.Auth{LDAP: &v1alpha1.LDAP{Parameter: tt.params}},
+				},
+			}
+
+			if err := applyLDAPConfiguration(guac, m); err != nil {
+				t.Fatal(err)
+			}
+
+			err = updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+				if got := deployment.Spec.Template.Spec.Containers[0].Env; !cmp.Equal(tt.want, got) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(tt.want, got))
+				}
+
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"maps"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -17,15 +18,66 @@ import (
 
 const (
 	GuacdDeploymentName = "guacd"
+	guacdPoolLabel      = "guacamole-operator.github.io/guacd-pool"
+	nameLabel           = "app.kubernetes.io/name"
+	// Label of all resources and pods of an instance. Set on resources by
+	// the reconciler and on pod templates by the transformer.
+	instanceLabel = "guacamole-operator.github.io/guacamole"
+
+	guacdCertificateName        = "guacd-tls"
+	guacdTLSVolumeName          = "guacd-tls"
+	guacdTLSMountPath           = "/etc/guacd/tls"
+	guacdCACertificateKey       = "ca.crt"
+	guacdCACertificateBundleKey = "guacd-ca.pem"
 )
 
 // Guacd transforms the guacd deployment manifest.
 func Guacd(client client.Client) declarative.ObjectTransform {
-	return func(_ context.Context, obj declarative.DeclarativeObject, m *manifest.Objects) error {
+	return func(ctx context.Context, obj declarative.DeclarativeObject, m *manifest.Objects) error {
 		guac := obj.(*v1alpha1.Guacamole)
 
+		if err := applyContainerImage(GuacdDeploymentName, "guacd", images(guac).Guacd, m); err != nil {
+			return err
+		}
+
 		if guac.Spec.Guacd != nil && guac.Spec.Guacd.Metadata != nil {
-			if err := applyAnnotations(guac.Spec.Guacd.Metadata, m); err != nil {
+			if err := applyAnnotations(GuacdDeploymentName, guac.Spec.Guacd.Metadata, m); err != nil {
+				return err
+			}
+		}
+
+		if guac.Spec.Recording != nil {
+			if err := applyRecordingVolume(guac, GuacdDeploymentName, "guacd", m); err != nil {
+				return err
+			}
+		}
+
+		if guac.Spec.Metrics != nil {
+			if err := applyGuacdMetrics(guac, m); err != nil {
+				return err
+			}
+		}
+
+		if guac.Spec.Guacd != nil && guac.Spec.Guacd.Template != nil {
+			err := updateDeployment(m, GuacdDeploymentName, func(deployment *appsv1.Deployment) error {
+				applyPodTemplate(guac.Spec.Guacd.Template, &deployment.Spec.Template.Spec)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if guac.Spec.Guacd != nil && guac.Spec.Guacd.TLS != nil {
+			if err := applyGuacdTLS(guac, m); err != nil {
+				return err
+			}
+		}
+
+		// Pools are rendered from the default guacd resources and
+		// therefore inherit their configuration.
+		for _, pool := range guac.Spec.GuacdPools {
+			if err := applyGuacdPool(pool, m); err != nil {
 				return err
 			}
 		}
@@ -34,9 +86,193 @@ func Guacd(client client.Client) declarative.ObjectTransform {
 	}
 }
 
-func applyAnnotations(objMeta *v1alpha1.ObjectMeta, m *manifest.Objects) error {
+// applyGuacdTLS configures guacd to accept TLS connections only and
+// requests a certificate from cert-manager if configured.
+func applyGuacdTLS(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	tls := guac.Spec.Guacd.TLS
+	secretName := tls.SecretName(guac.Name)
+
+	if tls.IssuerRef != nil {
+		certificate, err := guacdCertificate(guac, secretName)
+		if err != nil {
+			return err
+		}
+
+		m.Items = append(m.Items, certificate)
+	}
+
+	return updateDeployment(m, GuacdDeploymentName, func(deployment *appsv1.Deployment) error {
+		ensureVolume(deployment, corev1.Volume{
+			Name: guacdTLSVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secretName,
+				},
+			},
+		})
+
+		ensureContainerVolumeMount(deployment, "guacd", corev1.VolumeMount{
+			Name:      guacdTLSVolumeName,
+			ReadOnly:  true,
+			MountPath: guacdTLSMountPath,
+		})
+
+		for i, container := range deployment.Spec.Template.Spec.Containers {
+			if container.Name != "guacd" {
+				continue
+			}
+
+			envs := ensureEnvVar(container.Env, corev1.EnvVar{
+				Name:  "GUACD_SSL_CERT",
+				Value: guacdTLSMountPath + "/tls.crt",
+			})
+			envs = ensureEnvVar(envs, corev1.EnvVar{
+				Name:  "GUACD_SSL_KEY",
+				Value: guacdTLSMountPath + "/tls.key",
+			})
+
+			// Same invocation as the image default, plus certificate and key.
+			container.Command = []string{"/bin/sh", "-c"}
+			container.Args = []string{
+				`exec /opt/guacamole/sbin/guacd -b 0.0.0.0 -L "${GUACD_LOG_LEVEL:-info}" -f -C "$GUACD_SSL_CERT" -K "$GUACD_SSL_KEY"`,
+			}
+			container.Env = envs
+
+			deployment.Spec.Template.Spec.Containers[i] = container
+		}
+
+		return nil
+	})
+}
+
+// guacdCertificate returns a cert-manager certificate for guacd and all guacd pools.
+func guacdCertificate(guac *v1alpha1.Guacamole, secretName string) (*manifest.Object, error) {
+	services := []string{GuacdDeploymentName + "-" + guac.Name}
+	for _, pool := range guac.Spec.GuacdPools {
+		services = append(services, v1alpha1.GuacdPoolServiceName(guac.Name, pool.Name))
+	}
+
+	dnsNames := make([]any, 0, len(services)*3) //nolint:mnd
+	for _, svc := range services {
+		dnsNames = append(dnsNames,
+			svc,
+			svc+"."+guac.Namespace+".svc",
+			svc+"."+guac.Namespace+".svc.cluster.local",
+		)
+	}
+
+	issuer := guac.Spec.Guacd.TLS.IssuerRef
+
+	return manifest.NewObject(&unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata": map[string]any{
+			"name": guacdCertificateName,
+			"labels": map[string]any{
+				nameLabel: GuacdDeploymentName,
+			},
+		},
+		"spec": map[string]any{
+			"secretName": secretName,
+			"dnsNames":   dnsNames,
+			"issuerRef": map[string]any{
+				"name":  issuer.Name,
+				"kind":  issuer.Kind,
+				"group": issuer.Group,
+			},
+		},
+	}})
+}
+
+// applyGuacdPool adds a deployment and a service for a guacd pool.
+func applyGuacdPool(pool v1alpha1.GuacdPool, m *manifest.Objects) error {
+	name := GuacdDeploymentName + "-" + pool.Name
+	labels := map[string]string{
+		nameLabel:      name,
+		guacdPoolLabel: pool.Name,
+	}
+
+	for _, item := range m.Items {
+		if item.GetName() != GuacdDeploymentName {
+			continue
+		}
+
+		var obj runtime.Object
+
+		switch {
+		case isDeployment(item):
+			var deployment appsv1.Deployment
+			err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredObject().Object, &deployment)
+			if err != nil {
+				return fmt.Errorf("error converting deployment from unstructured: %w", err)
+			}
+
+			deployment.Name = name
+			deployment.Labels = mergeLabels(deployment.Labels, labels)
+			// Selectors must not overlap with the default deployment.
+			deployment.Spec.Selector.MatchLabels = maps.Clone(labels)
+			deployment.Spec.Template.Labels = mergeLabels(deployment.Spec.Template.Labels, labels)
+
+			obj = &deployment
+		case isService(item):
+			var service corev1.Service
+			err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredObject().Object, &service)
+			if err != nil {
+				return fmt.Errorf("error converting service from unstructured: %w", err)
+			}
+
+			service.Name = name
+			service.Labels = mergeLabels(service.Labels, labels)
+			service.Spec.Selector = maps.Clone(labels)
+
+			obj = &service
+		default:
+			continue
+		}
+
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+
+		newObj, err := manifest.NewObject(&unstructured.Unstructured{Object: u})
+		if err != nil {
+			return err
+		}
+
+		m.Items = append(m.Items, newObj)
+	}
+
+	if pool.Metadata != nil {
+		if err := applyAnnotations(name, pool.Metadata, m); err != nil {
+			return err
+		}
+	}
+
+	if pool.Template != nil {
+		return updateDeployment(m, name, func(deployment *appsv1.Deployment) error {
+			applyPodTemplate(pool.Template, &deployment.Spec.Template.Spec)
+			return nil
+		})
+	}
+
+	return nil
+}
+
+// mergeLabels merges labels into existing ones.
+func mergeLabels(existing, labels map[string]string) map[string]string {
+	if existing == nil {
+		existing = make(map[string]string, len(labels))
+	}
+
+	maps.Insert(existing, maps.All(labels))
+	return existing
+}
+
+// applyAnnotations merges annotations into a deployment and its pod template.
+func applyAnnotations(name string, objMeta *v1alpha1.ObjectMeta, m *manifest.Objects) error {
 	for idx, item := range m.Items {
-		if isDeployment(item) && item.GetName() == GuacdDeploymentName {
+		if isDeployment(item) && item.GetName() == name {
 			var deployment appsv1.Deployment
 			err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredObject().Object, &deployment)
 			if err != nil {
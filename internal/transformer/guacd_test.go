@@ -0,0 +1,275 @@
+package transformer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+const guacdManifest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: guacd
+  labels:
+    app.kubernetes.io/name: guacd
+spec:
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: guacd
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: guacd
+    spec:
+      containers:
+        - name: guacd
+          image: docker.io/guacamole/guacd:1.6.0
+          env:
+            - name: GUACD_LOG_LEVEL
+              value: info
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: guacd
+  labels:
+    app.kubernetes.io/name: guacd
+spec:
+  selector:
+    app.kubernetes.io/name: guacd
+  ports:
+    - name: guacd
+      port: 4822
+`
+
+func TestApplyGuacdPool(t *testing.T) {
+	tests := []struct {
+		name             string
+		pool             v1alpha1.GuacdPool
+		wantAnnotations  map[string]string
+		wantNodeSelector map[string]string
+	}{
+		{
+			name: "defaults",
+			pool: v1alpha1.GuacdPool{Name: "office"},
+		},
+		{
+			name: "overrides",
+			pool: v1alpha1.GuacdPool{
+				Name:     "office",
+				Metadata: &v1alpha1.ObjectMeta{Annotations: map[string]string{"example.com/pool": "office"}},
+				Template: &v1alpha1.PodTemplate{NodeSelector: map[string]string{"example.com/zone": "office"}},
+			},
+			wantAnnotations:  map[string]string{"example.com/pool": "office"},
+			wantNodeSelector: map[string]string{"example.com/zone": "office"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := manifest.ParseObjects(context.Background(), guacdManifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := applyGuacdPool(tt.pool, m); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := len(m.Items); got != 4 {
+				t.Fatalf("expected 4 objects, got %d", got)
+			}
+
+			wantLabels := map[string]string{
+				nameLabel:      "guacd-office",
+				guacdPoolLabel: "office",
+			}
+
+			err = updateDeployment(m, "guacd-office", func(deployment *appsv1.Deployment) error {
+				if !cmp.Equal(wantLabels, deployment.Labels) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantLabels, deployment.Labels))
+				}
+
+				if got := deployment.Spec.Selector.MatchLabels; !cmp.Equal(wantLabels, got) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantLabels, got))
+				}
+
+				if got := deployment.Spec.Template.Labels; !cmp.Equal(wantLabels, got) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantLabels, got))
+				}
+
+				if got := deployment.Spec.Template.Annotations; !cmp.Equal(tt.wantAnnotations, got) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(tt.wantAnnotations, got))
+				}
+
+				if got := deployment.Spec.Template.Spec.NodeSelector; !cmp.Equal(tt.wantNodeSelector, got) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(tt.wantNodeSelector, got))
+				}
+
+				// Containers are inherited from the default deployment.
+				if got := deployment.Spec.Template.Spec.Containers[0].Image; got != "docker.io/guacamole/guacd:1.6.0" {
+					t.Errorf("unexpected image %q", got)
+				}
+
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = updateService(m, "guacd-office", func(service *corev1.Service) error {
+				if !cmp.Equal(wantLabels, service.Labels) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantLabels, service.Labels))
+				}
+
+				if !cmp.Equal(wantLabels, service.Spec.Selector) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantLabels, service.Spec.Selector))
+				}
+
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The default deployment keeps its selector.
+			err = updateDeployment(m, GuacdDeploymentName, func(deployment *appsv1.Deployment) error {
+				want := map[string]string{nameLabel: GuacdDeploymentName}
+				if got := deployment.Spec.Selector.MatchLabels; !cmp.Equal(want, got) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+				}
+
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestApplyGuacdTLS(t *testing.T) {
+	tests := []struct {
+		name            string
+		tls             v1alpha1.GuacdTLS
+		wantSecret      string
+		wantCertificate map[string]any
+	}{
+		{
+			name:       "secret",
+			tls:        v1alpha1.GuacdTLS{SecretRef: &corev1.LocalObjectReference{Name: "guacd-cert"}},
+			wantSecret: "guacd-cert",
+		},
+		{
+			name:       "issuer",
+			tls:        v1alpha1.GuacdTLS{IssuerRef: &v1alpha1.IssuerRef{Name: "ca", Kind: "ClusterIssuer", Group: "cert-manager.io"}},
+			wantSecret: "guacd-tls-example",
+			wantCertificate: map[string]any{
+				"secretName": "guacd-tls-example",
+				"dnsNames": []any{
+					"guacd-example",
+					"guacd-example.default.svc",
+					"guacd-example.default.svc.cluster.local",
+					"guacd-office-example",
+					"guacd-office-example.default.svc",
+					"guacd-office-example.default.svc.cluster.local",
+				},
+				"issuerRef": map[string]any{
+					"name":  "ca",
+					"kind":  "ClusterIssuer",
+					"group": "cert-manager.io",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := manifest.ParseObjects(context.Background(), guacdManifest)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			guac := &v1alpha1.Guacamole{
+				ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+				Spec: v1alpha1.GuacamoleSpec{
+					Guacd:      &v1alpha1.Guacd{TLS: &tt.tls},
+					GuacdPools: []v1alpha1.GuacdPool{{Name: "office"}},
+				},
+			}
+
+			if err := applyGuacdTLS(guac, m); err != nil {
+				t.Fatal(err)
+			}
+
+			err = updateDeployment(m, GuacdDeploymentName, func(deployment *appsv1.Deployment) error {
+				wantVolumes := []corev1.Volume{{
+					Name: guacdTLSVolumeName,
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{SecretName: tt.wantSecret},
+					},
+				}}
+
+				if got := deployment.Spec.Template.Spec.Volumes; !cmp.Equal(wantVolumes, got) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantVolumes, got))
+				}
+
+				container := deployment.Spec.Template.Spec.Containers[0]
+
+				wantMounts := []corev1.VolumeMount{{Name: guacdTLSVolumeName, ReadOnly: true, MountPath: guacdTLSMountPath}}
+				if !cmp.Equal(wantMounts, container.VolumeMounts) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantMounts, container.VolumeMounts))
+				}
+
+				wantEnv := []corev1.EnvVar{
+					{Name: "GUACD_LOG_LEVEL", Value: "info"},
+					{Name: "GUACD_SSL_CERT", Value: "/etc/guacd/tls/tls.crt"},
+					{Name: "GUACD_SSL_KEY", Value: "/etc/guacd/tls/tls.key"},
+				}
+				if !cmp.Equal(wantEnv, container.Env) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantEnv, container.Env))
+				}
+
+				wantArgs := []string{`exec /opt/guacamole/sbin/guacd -b 0.0.0.0 -L "${GUACD_LOG_LEVEL:-info}" -f -C "$GUACD_SSL_CERT" -K "$GUACD_SSL_KEY"`}
+				if !cmp.Equal(wantArgs, container.Args) {
+					t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantArgs, container.Args))
+				}
+
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var certificate map[string]any
+			for _, item := range m.Items {
+				if item.Kind == "Certificate" {
+					certificate = item.UnstructuredObject().Object
+				}
+			}
+
+			if tt.wantCertificate == nil {
+				if certificate != nil {
+					t.Errorf("expected no certificate, got %v", certificate)
+				}
+				return
+			}
+
+			if certificate == nil {
+				t.Fatal("expected certificate")
+			}
+
+			if got := certificate["spec"]; !cmp.Equal(tt.wantCertificate, got) {
+				t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(tt.wantCertificate, got))
+			}
+		})
+	}
+}
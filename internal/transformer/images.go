@@ -0,0 +1,161 @@
+package transformer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+// defaultRegistry is the registry of image references without a domain.
+const defaultRegistry = "docker.io"
+
+// Images rewrites all image references of the manifest to the registry
+// mirror. References are rewritten to `<mirror>/<repository>`, e.g.
+// `docker.io/guacamole/guacd:1.6.0` to `registry.example.com/guacamole/guacd:1.6.0`.
+// Executed after all other transformations to include images added by them.
+func Images(mirror string) declarative.ObjectTransform {
+	return func(_ context.Context, _ declarative.DeclarativeObject, m *manifest.Objects) error {
+		if mirror == "" {
+			return nil
+		}
+
+		return updatePodSpecs(m, func(podSpec *corev1.PodSpec) {
+			for i := range podSpec.InitContainers {
+				podSpec.InitContainers[i].Image = mirrorImage(mirror, podSpec.InitContainers[i].Image)
+			}
+
+			for i := range podSpec.Containers {
+				podSpec.Containers[i].Image = mirrorImage(mirror, podSpec.Containers[i].Image)
+			}
+
+			for i := range podSpec.Volumes {
+				if image := podSpec.Volumes[i].Image; image != nil {
+					image.Reference = mirrorImage(mirror, image.Reference)
+				}
+			}
+		})
+	}
+}
+
+// images returns the image overrides of an instance.
+func images(guac *v1alpha1.Guacamole) v1alpha1.Images {
+	if guac.Spec.Images == nil {
+		return v1alpha1.Images{}
+	}
+
+	return *guac.Spec.Images
+}
+
+// imageOrDefault returns the override of an image if set.
+func imageOrDefault(override, image string) string {
+	if override != "" {
+		return override
+	}
+
+	return image
+}
+
+// applyContainerImage overrides the image of a container of a deployment.
+// Applied before other transformations so that containers derived from
+// it, e.g. database initialization and pools, use the override as well.
+func applyContainerImage(deploymentName, container, image string, m *manifest.Objects) error {
+	if image == "" {
+		return nil
+	}
+
+	return updateDeployment(m, deploymentName, func(deployment *appsv1.Deployment) error {
+		for i := range deployment.Spec.Template.Spec.Containers {
+			if deployment.Spec.Template.Spec.Containers[i].Name == container {
+				deployment.Spec.Template.Spec.Containers[i].Image = image
+			}
+		}
+
+		return nil
+	})
+}
+
+// mirrorImage rewrites an image reference to the registry mirror.
+// References already pointing to the mirror are not changed.
+func mirrorImage(mirror, image string) string {
+	mirror = strings.TrimSuffix(mirror, "/")
+
+	if image == "" || strings.HasPrefix(image, mirror+"/") {
+		return image
+	}
+
+	repository := image
+
+	// The first path component is a registry if it looks like a host.
+	if domain, rest, ok := strings.Cut(image, "/"); ok &&
+		(strings.ContainsAny(domain, ".:") || domain == "localhost") {
+		repository = rest
+	} else if !ok {
+		// Official images of the default registry.
+		repository = "library/" + image
+	}
+
+	return mirror + "/" + repository
+}
+
+// updatePodSpecs applies fn to the pod specs of all workloads of the manifest.
+func updatePodSpecs(m *manifest.Objects, fn func(*corev1.PodSpec)) error {
+	for idx, item := range m.Items {
+		var fields []string
+
+		switch item.Kind {
+		case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+			fields = []string{"spec", "template", "spec"}
+		case "CronJob":
+			fields = []string{"spec", "jobTemplate", "spec", "template", "spec"}
+		case "Pod":
+			fields = []string{"spec"}
+		default:
+			continue
+		}
+
+		u := item.UnstructuredObject().DeepCopy()
+
+		spec, found, err := unstructured.NestedMap(u.Object, fields...)
+		if err != nil {
+			return fmt.Errorf("error getting pod spec of %s %s: %w", item.Kind, item.GetName(), err)
+		}
+
+		if !found {
+			continue
+		}
+
+		var podSpec corev1.PodSpec
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, &podSpec); err != nil {
+			return fmt.Errorf("error converting pod spec from unstructured: %w", err)
+		}
+
+		fn(&podSpec)
+
+		spec, err = runtime.DefaultUnstructuredConverter.ToUnstructured(&podSpec)
+		if err != nil {
+			return err
+		}
+
+		if err := unstructured.SetNestedMap(u.Object, spec, fields...); err != nil {
+			return err
+		}
+
+		obj, err := manifest.NewObject(u)
+		if err != nil {
+			return err
+		}
+
+		m.Items[idx] = obj
+	}
+
+	return nil
+}
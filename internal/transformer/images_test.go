@@ -0,0 +1,114 @@
+package transformer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+)
+
+func TestMirrorImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"docker.io/guacamole/guacd:1.6.0", "registry.example.com/mirror/guacamole/guacd:1.6.0"},
+		{extensionDLImage, "registry.example.com/mirror/" + strings.TrimPrefix(extensionDLImage, "ghcr.io/")},
+		{"localhost:5000/guacamole@sha256:abc", "registry.example.com/mirror/guacamole@sha256:abc"},
+		{"guacamole/guacamole:1.6.0", "registry.example.com/mirror/guacamole/guacamole:1.6.0"},
+		{"postgres:alpine", "registry.example.com/mirror/library/postgres:alpine"},
+		{"registry.example.com/mirror/postgres:alpine", "registry.example.com/mirror/postgres:alpine"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := mirrorImage("registry.example.com/mirror/", tt.image); got != tt.want {
+			t.Errorf("mirrorImage(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
+
+func TestImages(t *testing.T) {
+	m, err := manifest.ParseObjects(context.Background(), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: guacamole
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: extension-dl
+          image: `+extensionDLImage+`
+      containers:
+        - name: guacamole
+          image: docker.io/guacamole/guacamole:1.6.0
+      volumes:
+        - name: branding
+          image:
+            reference: ghcr.io/example/branding:1.0.0
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: recording-retention
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: recording-retention
+              image: `+recordingRetentionImage+`
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: guacamole
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Images("registry.example.com")(context.Background(), nil, m); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+
+	for _, item := range m.Items {
+		u := item.UnstructuredObject().Object
+
+		for _, fields := range [][]string{
+			{"spec", "template", "spec", "initContainers"},
+			{"spec", "template", "spec", "containers"},
+			{"spec", "template", "spec", "volumes"},
+			{"spec", "jobTemplate", "spec", "template", "spec", "containers"},
+		} {
+			list, _, _ := unstructured.NestedSlice(u, fields...)
+			for _, entry := range list {
+				image, _, _ := unstructured.NestedString(entry.(map[string]any), "image")
+				if image == "" {
+					image, _, _ = unstructured.NestedString(entry.(map[string]any), "image", "reference")
+				}
+
+				got = append(got, image)
+			}
+		}
+	}
+
+	// Pinned images of the operator are mirrored with their actual references.
+	want := []string{
+		"registry.example.com/" + strings.TrimPrefix(extensionDLImage, "ghcr.io/"),
+		"registry.example.com/guacamole/guacamole:1.6.0",
+		"registry.example.com/example/branding:1.0.0",
+		"registry.example.com/" + strings.TrimPrefix(recordingRetentionImage, "ghcr.io/"),
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+}
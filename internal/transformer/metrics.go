@@ -0,0 +1,191 @@
+package transformer
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+const (
+	metricsPortName = "metrics"
+
+	jmxExporterName        = "jmx-exporter"
+	jmxExporterImage       = "ghcr.io/guacamole-operator/jmx-exporter:1.0.0"
+	jmxExporterPort  int32 = 9404
+	// Agent and configuration are copied to the volume by the init container.
+	jmxExporterMountPath = "/opt/jmx-exporter"
+
+	guacdExporterName        = "guacd-exporter"
+	guacdExporterImage       = "ghcr.io/guacamole-operator/guacd-exporter:1.0.0"
+	guacdExporterPort  int32 = 9405
+
+	serviceMonitorName = "guacamole"
+)
+
+// applyGuacamoleMetrics injects the JMX exporter agent into the web
+// application and exposes its metrics port.
+func applyGuacamoleMetrics(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	err := updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		deployment.Spec.Template.Spec.InitContainers = append(deployment.Spec.Template.Spec.InitContainers, corev1.Container{
+			Name:  jmxExporterName,
+			Image: imageOrDefault(images(guac).JMXExporter, jmxExporterImage),
+		})
+
+		ensureVolume(deployment, corev1.Volume{
+			Name: jmxExporterName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+
+		ensureInitContainerVolumeMount(deployment, jmxExporterName, corev1.VolumeMount{
+			Name:      jmxExporterName,
+			MountPath: "/agent",
+		})
+
+		ensureContainerVolumeMount(deployment, "guacamole", corev1.VolumeMount{
+			Name:      jmxExporterName,
+			ReadOnly:  true,
+			MountPath: jmxExporterMountPath,
+		})
+
+		container := &deployment.Spec.Template.Spec.Containers[0]
+
+		// Tomcat passes CATALINA_OPTS to the JVM.
+		agent := "-javaagent:" + jmxExporterMountPath + "/jmx_prometheus_javaagent.jar=" +
+			strconv.Itoa(int(jmxExporterPort)) + ":" + jmxExporterMountPath + "/config.yaml"
+
+		opts := []string{agent}
+		if idx := slices.IndexFunc(container.Env, func(env corev1.EnvVar) bool { return env.Name == "CATALINA_OPTS" }); idx >= 0 {
+			opts = append([]string{container.Env[idx].Value}, opts...)
+		}
+
+		container.Env = ensureEnvVar(container.Env, corev1.EnvVar{
+			Name:  "CATALINA_OPTS",
+			Value: strings.Join(opts, " "),
+		})
+
+		container.Ports = ensureContainerPort(container.Ports, corev1.ContainerPort{
+			Name:          metricsPortName,
+			ContainerPort: jmxExporterPort,
+			Protocol:      corev1.ProtocolTCP,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return applyMetricsServicePort(GuacamoleDeploymentName, jmxExporterPort, m)
+}
+
+// applyGuacdMetrics adds the exporter sidecar to guacd and exposes its
+// metrics port. Applied before pools are rendered to include them.
+func applyGuacdMetrics(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	err := updateDeployment(m, GuacdDeploymentName, func(deployment *appsv1.Deployment) error {
+		deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers, corev1.Container{
+			Name:  guacdExporterName,
+			Image: imageOrDefault(images(guac).GuacdExporter, guacdExporterImage),
+			Args: []string{
+				"-listen-address=:" + strconv.Itoa(int(guacdExporterPort)),
+			},
+			Ports: []corev1.ContainerPort{{
+				Name:          metricsPortName,
+				ContainerPort: guacdExporterPort,
+				Protocol:      corev1.ProtocolTCP,
+			}},
+		})
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return applyMetricsServicePort(GuacdDeploymentName, guacdExporterPort, m)
+}
+
+// applyMetricsServicePort adds the metrics port to a service.
+func applyMetricsServicePort(name string, port int32, m *manifest.Objects) error {
+	return updateService(m, name, func(service *corev1.Service) error {
+		if slices.ContainsFunc(service.Spec.Ports, func(p corev1.ServicePort) bool { return p.Name == metricsPortName }) {
+			return nil
+		}
+
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+			Name:       metricsPortName,
+			Port:       port,
+			TargetPort: intstr.FromString(metricsPortName),
+			Protocol:   corev1.ProtocolTCP,
+		})
+
+		return nil
+	})
+}
+
+// applyServiceMonitor adds a ServiceMonitor scraping the metrics ports
+// of the services of the instance to the manifest.
+func applyServiceMonitor(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	monitor := guac.Spec.Metrics.ServiceMonitor
+
+	labels := map[string]any{nameLabel: serviceMonitorName}
+	for k, v := range monitor.Labels {
+		labels[k] = v
+	}
+
+	endpoint := map[string]any{
+		"port": metricsPortName,
+		"path": "/metrics",
+	}
+
+	if monitor.Interval != nil {
+		endpoint["interval"] = monitor.Interval.Duration.String()
+	}
+
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "monitoring.coreos.com/v1",
+		"kind":       "ServiceMonitor",
+		"metadata": map[string]any{
+			"name":   serviceMonitorName,
+			"labels": labels,
+		},
+		"spec": map[string]any{
+			"selector": map[string]any{
+				"matchLabels": map[string]any{
+					instanceLabel: guac.Name,
+				},
+			},
+			"endpoints": []any{endpoint},
+		},
+	}}
+
+	obj, err := manifest.NewObject(u)
+	if err != nil {
+		return err
+	}
+
+	m.Items = append(m.Items, obj)
+
+	return nil
+}
+
+// ensureContainerPort adds or replaces a port by name.
+func ensureContainerPort(ports []corev1.ContainerPort, port corev1.ContainerPort) []corev1.ContainerPort {
+	for i, p := range ports {
+		if p.Name == port.Name {
+			ports[i] = port
+			return ports
+		}
+	}
+
+	return append(ports, port)
+}
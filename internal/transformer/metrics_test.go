@@ -0,0 +1,152 @@
+package transformer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+func TestApplyMetrics(t *testing.T) {
+	m, err := manifest.ParseObjects(context.Background(), `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: guacamole
+spec:
+  template:
+    spec:
+      containers:
+        - name: guacamole
+          env:
+            - name: CATALINA_OPTS
+              value: -Xmx1g
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: guacamole
+spec:
+  ports:
+    - name: http
+      port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: guacd
+spec:
+  template:
+    spec:
+      containers:
+        - name: guacd
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: guacd
+spec:
+  ports:
+    - name: guacd
+      port: 4822
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	guac := &v1alpha1.Guacamole{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Spec: v1alpha1.GuacamoleSpec{
+			Images: &v1alpha1.Images{GuacdExporter: "registry.example.com/guacd-exporter:1.0.0"},
+			Metrics: &v1alpha1.Metrics{
+				ServiceMonitor: &v1alpha1.ServiceMonitor{
+					Labels:   map[string]string{"release": "prometheus"},
+					Interval: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+		},
+	}
+
+	if err := applyGuacdMetrics(guac, m); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyGuacamoleMetrics(guac, m); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyServiceMonitor(guac, m); err != nil {
+		t.Fatal(err)
+	}
+
+	err = updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		want := "-Xmx1g -javaagent:/opt/jmx-exporter/jmx_prometheus_javaagent.jar=9404:/opt/jmx-exporter/config.yaml"
+		if got := deployment.Spec.Template.Spec.Containers[0].Env[0].Value; got != want {
+			t.Errorf("CATALINA_OPTS = %q, want %q", got, want)
+		}
+
+		if got := deployment.Spec.Template.Spec.InitContainers[0].Image; got != jmxExporterImage {
+			t.Errorf("jmx exporter image = %q, want %q", got, jmxExporterImage)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = updateDeployment(m, GuacdDeploymentName, func(deployment *appsv1.Deployment) error {
+		if got := deployment.Spec.Template.Spec.Containers[1].Image; got != "registry.example.com/guacd-exporter:1.0.0" {
+			t.Errorf("unexpected guacd exporter image %q", got)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = updateService(m, GuacdDeploymentName, func(service *corev1.Service) error {
+		want := corev1.ServicePort{
+			Name:       metricsPortName,
+			Port:       guacdExporterPort,
+			TargetPort: intstr.FromString(metricsPortName),
+			Protocol:   corev1.ProtocolTCP,
+		}
+
+		if !cmp.Equal(want, service.Spec.Ports[1]) {
+			t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, service.Spec.Ports[1]))
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	monitor := m.Items[len(m.Items)-1].UnstructuredObject().Object
+
+	selector, _, _ := unstructured.NestedStringMap(monitor, "spec", "selector", "matchLabels")
+	if want := map[string]string{"guacamole-operator.github.io/guacamole": "example"}; !cmp.Equal(want, selector) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, selector))
+	}
+
+	labels, _, _ := unstructured.NestedStringMap(monitor, "metadata", "labels")
+	if labels["release"] != "prometheus" {
+		t.Errorf("expected additional labels, got %v", labels)
+	}
+
+	endpoints, _, _ := unstructured.NestedSlice(monitor, "spec", "endpoints")
+	if interval := endpoints[0].(map[string]any)["interval"]; interval != "1m0s" {
+		t.Errorf("interval = %v, want 1m0s", interval)
+	}
+}
@@ -0,0 +1,225 @@
+package transformer
+
+import (
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+const (
+	dnsPort = 53
+
+	// Label of the operator pods, which connect to the API of the web
+	// application.
+	operatorLabel      = "control-plane"
+	operatorLabelValue = "controller-manager"
+)
+
+// applyNetworkPolicies adds the NetworkPolicies of the web application
+// and guacd, including pools, to the manifest.
+func applyNetworkPolicies(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	policies := []networkingv1.NetworkPolicy{
+		guacamoleNetworkPolicy(guac),
+		guacdNetworkPolicy(guac),
+	}
+
+	for i := range policies {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&policies[i])
+		if err != nil {
+			return err
+		}
+
+		obj, err := manifest.NewObject(&unstructured.Unstructured{Object: u})
+		if err != nil {
+			return err
+		}
+
+		m.Items = append(m.Items, obj)
+	}
+
+	return nil
+}
+
+// guacamoleNetworkPolicy allows ingress to the web application from the
+// operator and the configured namespaces. Egress is restricted to guacd
+// and the configured rules.
+func guacamoleNetworkPolicy(guac *v1alpha1.Guacamole) networkingv1.NetworkPolicy {
+	spec := guac.Spec.NetworkPolicy
+
+	peers := []networkingv1.NetworkPolicyPeer{operatorPeer()}
+
+	for _, namespace := range spec.IngressNamespaces {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{corev1.LabelMetadataName: namespace},
+			},
+		})
+	}
+
+	policy := newNetworkPolicy(GuacamoleDeploymentName, guacamoleSelector(guac))
+
+	policy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{
+		From:  peers,
+		Ports: []networkingv1.NetworkPolicyPort{namedPort("http")},
+	}}
+
+	if rule := metricsIngressRule(guac); rule != nil {
+		policy.Spec.Ingress = append(policy.Spec.Ingress, *rule)
+	}
+
+	if len(spec.Egress) > 0 {
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		policy.Spec.Egress = append([]networkingv1.NetworkPolicyEgressRule{
+			dnsEgressRule(),
+			{
+				To:    []networkingv1.NetworkPolicyPeer{{PodSelector: guacdSelector(guac)}},
+				Ports: []networkingv1.NetworkPolicyPort{namedPort("guacd")},
+			},
+		}, spec.Egress...)
+	}
+
+	return policy
+}
+
+// guacdNetworkPolicy allows ingress to guacd from the web application
+// only. Egress is restricted to the CIDRs of the target hosts if set.
+func guacdNetworkPolicy(guac *v1alpha1.Guacamole) networkingv1.NetworkPolicy {
+	spec := guac.Spec.NetworkPolicy
+
+	policy := newNetworkPolicy(GuacdDeploymentName, guacdSelector(guac))
+
+	policy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{{
+		From:  []networkingv1.NetworkPolicyPeer{{PodSelector: guacamoleSelector(guac)}},
+		Ports: []networkingv1.NetworkPolicyPort{namedPort("guacd")},
+	}}
+
+	if rule := metricsIngressRule(guac); rule != nil {
+		policy.Spec.Ingress = append(policy.Spec.Ingress, *rule)
+	}
+
+	if len(spec.TargetCIDRs) > 0 {
+		targets := networkingv1.NetworkPolicyEgressRule{}
+		for _, cidr := range spec.TargetCIDRs {
+			targets.To = append(targets.To, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{CIDR: cidr},
+			})
+		}
+
+		policy.Spec.PolicyTypes = append(policy.Spec.PolicyTypes, networkingv1.PolicyTypeEgress)
+		policy.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{dnsEgressRule(), targets}
+	}
+
+	return policy
+}
+
+// metricsIngressRule allows scraping metrics from the configured
+// namespaces if metrics are enabled.
+func metricsIngressRule(guac *v1alpha1.Guacamole) *networkingv1.NetworkPolicyIngressRule {
+	if guac.Spec.Metrics == nil || len(guac.Spec.NetworkPolicy.MetricsNamespaces) == 0 {
+		return nil
+	}
+
+	rule := &networkingv1.NetworkPolicyIngressRule{
+		Ports: []networkingv1.NetworkPolicyPort{namedPort(metricsPortName)},
+	}
+
+	for _, namespace := range guac.Spec.NetworkPolicy.MetricsNamespaces {
+		rule.From = append(rule.From, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{corev1.LabelMetadataName: namespace},
+			},
+		})
+	}
+
+	return rule
+}
+
+func newNetworkPolicy(name string, selector *metav1.LabelSelector) networkingv1.NetworkPolicy {
+	return networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				nameLabel: name,
+			},
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *selector,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// guacamoleSelector selects the pods of the web application of the instance.
+func guacamoleSelector(guac *v1alpha1.Guacamole) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			nameLabel:     GuacamoleDeploymentName,
+			instanceLabel: guac.Name,
+		},
+	}
+}
+
+// guacdSelector selects the pods of the default guacd deployment and
+// of all pools of the instance.
+func guacdSelector(guac *v1alpha1.Guacamole) *metav1.LabelSelector {
+	names := []string{GuacdDeploymentName}
+	for _, pool := range guac.Spec.GuacdPools {
+		names = append(names, GuacdDeploymentName+"-"+pool.Name)
+	}
+
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{instanceLabel: guac.Name},
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      nameLabel,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   names,
+		}},
+	}
+}
+
+// operatorPeer selects the operator pods. The namespace of the operator is
+// provided via the downward API, otherwise operator pods of all namespaces
+// are selected.
+func operatorPeer() networkingv1.NetworkPolicyPeer {
+	namespaceSelector := &metav1.LabelSelector{}
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		namespaceSelector.MatchLabels = map[string]string{corev1.LabelMetadataName: namespace}
+	}
+
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: namespaceSelector,
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{operatorLabel: operatorLabelValue},
+		},
+	}
+}
+
+// dnsEgressRule allows name resolution via any DNS server.
+func dnsEgressRule() networkingv1.NetworkPolicyEgressRule {
+	return networkingv1.NetworkPolicyEgressRule{
+		Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: ptr.To(corev1.ProtocolUDP), Port: ptr.To(intstr.FromInt32(dnsPort))},
+			{Protocol: ptr.To(corev1.ProtocolTCP), Port: ptr.To(intstr.FromInt32(dnsPort))},
+		},
+	}
+}
+
+func namedPort(name string) networkingv1.NetworkPolicyPort {
+	return networkingv1.NetworkPolicyPort{
+		Protocol: ptr.To(corev1.ProtocolTCP),
+		Port:     ptr.To(intstr.FromString(name)),
+	}
+}
@@ -0,0 +1,105 @@
+package transformer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+func TestNetworkPolicies(t *testing.T) {
+	t.Setenv("POD_NAMESPACE", "guacamole-operator-system")
+
+	database := networkingv1.NetworkPolicyEgressRule{
+		To: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.1.10/32"}}},
+	}
+
+	guac := &v1alpha1.Guacamole{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Spec: v1alpha1.GuacamoleSpec{
+			GuacdPools: []v1alpha1.GuacdPool{{Name: "dmz"}},
+			NetworkPolicy: &v1alpha1.NetworkPolicy{
+				IngressNamespaces: []string{"ingress-nginx"},
+				Egress:            []networkingv1.NetworkPolicyEgressRule{database},
+				TargetCIDRs:       []string{"10.1.0.0/16"},
+			},
+		},
+	}
+
+	// Pods are selected within the instance only.
+	guacamolePods := &metav1.LabelSelector{
+		MatchLabels: map[string]string{nameLabel: "guacamole", instanceLabel: "example"},
+	}
+
+	guacdPods := &metav1.LabelSelector{
+		MatchLabels: map[string]string{instanceLabel: "example"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      nameLabel,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{"guacd", "guacd-dmz"},
+		}},
+	}
+
+	guacamole := guacamoleNetworkPolicy(guac)
+
+	if !cmp.Equal(*guacamolePods, guacamole.Spec.PodSelector) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(*guacamolePods, guacamole.Spec.PodSelector))
+	}
+
+	wantFrom := []networkingv1.NetworkPolicyPeer{
+		{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: "guacamole-operator-system"}},
+			PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{operatorLabel: operatorLabelValue}},
+		},
+		{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelMetadataName: "ingress-nginx"}},
+		},
+	}
+
+	if !cmp.Equal(wantFrom, guacamole.Spec.Ingress[0].From) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantFrom, guacamole.Spec.Ingress[0].From))
+	}
+
+	wantEgress := []networkingv1.NetworkPolicyEgressRule{
+		dnsEgressRule(),
+		{
+			To:    []networkingv1.NetworkPolicyPeer{{PodSelector: guacdPods}},
+			Ports: []networkingv1.NetworkPolicyPort{namedPort("guacd")},
+		},
+		database,
+	}
+
+	if !cmp.Equal(wantEgress, guacamole.Spec.Egress) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantEgress, guacamole.Spec.Egress))
+	}
+
+	guacd := guacdNetworkPolicy(guac)
+
+	if !cmp.Equal(*guacdPods, guacd.Spec.PodSelector) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(*guacdPods, guacd.Spec.PodSelector))
+	}
+
+	wantFrom = []networkingv1.NetworkPolicyPeer{{PodSelector: guacamolePods}}
+	if !cmp.Equal(wantFrom, guacd.Spec.Ingress[0].From) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantFrom, guacd.Spec.Ingress[0].From))
+	}
+
+	wantTypes := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}
+	if !cmp.Equal(wantTypes, guacd.Spec.PolicyTypes) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(wantTypes, guacd.Spec.PolicyTypes))
+	}
+
+	// Egress is not restricted unless configured.
+	guac.Spec.NetworkPolicy = &v1alpha1.NetworkPolicy{}
+
+	for _, policy := range []networkingv1.NetworkPolicy{guacamoleNetworkPolicy(guac), guacdNetworkPolicy(guac)} {
+		want := []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+		if !cmp.Equal(want, policy.Spec.PolicyTypes) {
+			t.Errorf("%s: unexpected diff (-want +got):\n%s", policy.Name, cmp.Diff(want, policy.Spec.PolicyTypes))
+		}
+	}
+}
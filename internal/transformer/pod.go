@@ -0,0 +1,81 @@
+package transformer
+
+import (
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+// applyPodTemplate applies the overrides of a pod template to a pod spec.
+func applyPodTemplate(template *v1alpha1.PodTemplate, spec *corev1.PodSpec) {
+	if template.NodeSelector != nil {
+		if spec.NodeSelector == nil {
+			spec.NodeSelector = make(map[string]string)
+		}
+		maps.Insert(spec.NodeSelector, maps.All(template.NodeSelector))
+	}
+
+	if template.Tolerations != nil {
+		spec.Tolerations = template.Tolerations
+	}
+
+	if template.Affinity != nil {
+		spec.Affinity = template.Affinity
+	}
+
+	if template.PriorityClassName != "" {
+		spec.PriorityClassName = template.PriorityClassName
+	}
+
+	if template.ImagePullSecrets != nil {
+		spec.ImagePullSecrets = template.ImagePullSecrets
+	}
+
+	if template.SecurityContext != nil {
+		spec.SecurityContext = template.SecurityContext
+	}
+
+	for _, c := range template.Containers {
+		for i := range spec.InitContainers {
+			if spec.InitContainers[i].Name == c.Name {
+				applyContainerTemplate(c, &spec.InitContainers[i])
+			}
+		}
+
+		for i := range spec.Containers {
+			if spec.Containers[i].Name == c.Name {
+				applyContainerTemplate(c, &spec.Containers[i])
+			}
+		}
+	}
+}
+
+// applyContainerTemplate applies the overrides of a container template to a container.
+func applyContainerTemplate(template v1alpha1.ContainerTemplate, container *corev1.Container) {
+	if template.Resources != nil {
+		container.Resources = *template.Resources
+	}
+
+	if template.SecurityContext != nil {
+		container.SecurityContext = template.SecurityContext
+	}
+
+	for _, env := range template.Env {
+		container.Env = ensureEnvVar(container.Env, env)
+	}
+}
+
+// ensureEnvVar replaces an environment variable with the same name
+// or appends it.
+func ensureEnvVar(envs []corev1.EnvVar, env corev1.EnvVar) []corev1.EnvVar {
+	for i, e := range envs {
+		if e.Name == env.Name {
+			envs[i] = env
+			return envs
+		}
+	}
+
+	return append(envs, env)
+}
@@ -0,0 +1,71 @@
+package transformer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+func TestApplyPodTemplate(t *testing.T) {
+	spec := corev1.PodSpec{
+		NodeSelector: map[string]string{"zone": "a"},
+		InitContainers: []corev1.Container{{
+			Name: "load-db",
+		}},
+		Containers: []corev1.Container{{
+			Name: "guacamole",
+			Env: []corev1.EnvVar{
+				{Name: "GUACD_HOSTNAME", Value: "guacd"},
+			},
+		}},
+	}
+
+	resources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+
+	template := &v1alpha1.PodTemplate{
+		NodeSelector:      map[string]string{"os": "linux"},
+		PriorityClassName: "high",
+		Containers: []v1alpha1.ContainerTemplate{{
+			Name:      "guacamole",
+			Resources: &resources,
+			Env: []corev1.EnvVar{
+				{Name: "GUACD_HOSTNAME", Value: "other"},
+				{Name: "EXTRA", Value: "value"},
+			},
+		}, {
+			Name:      "load-db",
+			Resources: &resources,
+		}},
+	}
+
+	applyPodTemplate(template, &spec)
+
+	want := corev1.PodSpec{
+		NodeSelector:      map[string]string{"zone": "a", "os": "linux"},
+		PriorityClassName: "high",
+		InitContainers: []corev1.Container{{
+			Name:      "load-db",
+			Resources: resources,
+		}},
+		Containers: []corev1.Container{{
+			Name:      "guacamole",
+			Resources: resources,
+			Env: []corev1.EnvVar{
+				{Name: "GUACD_HOSTNAME", Value: "other"},
+				{Name: "EXTRA", Value: "value"},
+			},
+		}},
+	}
+
+	if !cmp.Equal(want, spec) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, spec))
+	}
+}
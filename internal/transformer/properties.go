@@ -0,0 +1,111 @@
+package transformer
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+const (
+	propertiesVolumeName = "properties"
+	propertiesMountPath  = "/etc/guacamole/properties.d"
+	logbackVolumeName    = "logback"
+	logbackMountPath     = "/etc/guacamole/logback"
+	logbackFile          = "logback.xml"
+)
+
+// applyPropertiesConfiguration mounts the referenced properties files. The
+// files are merged into `guacamole.properties` by the container in the
+// order of their names.
+func applyPropertiesConfiguration(properties []v1alpha1.PropertiesSource, m *manifest.Objects) error {
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		ensureVolume(deployment, corev1.Volume{
+			Name: propertiesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: propertiesProjections(properties),
+				},
+			},
+		})
+
+		ensureContainerVolumeMount(deployment, "guacamole", corev1.VolumeMount{
+			Name:      propertiesVolumeName,
+			ReadOnly:  true,
+			MountPath: propertiesMountPath,
+		})
+
+		return nil
+	})
+}
+
+// propertiesProjections returns the volume projections of properties
+// files, prefixed with their position to keep the order.
+func propertiesProjections(properties []v1alpha1.PropertiesSource) []corev1.VolumeProjection {
+	projections := make([]corev1.VolumeProjection, 0, len(properties))
+
+	for i, p := range properties {
+		path := fmt.Sprintf("%03d.properties", i)
+
+		switch {
+		case p.ConfigMapKeyRef != nil:
+			projections = append(projections, corev1.VolumeProjection{
+				ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: p.ConfigMapKeyRef.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: p.ConfigMapKeyRef.Key, Path: path}},
+					Optional:             p.ConfigMapKeyRef.Optional,
+				},
+			})
+		case p.SecretKeyRef != nil:
+			projections = append(projections, corev1.VolumeProjection{
+				Secret: &corev1.SecretProjection{
+					LocalObjectReference: p.SecretKeyRef.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: p.SecretKeyRef.Key, Path: path}},
+					Optional:             p.SecretKeyRef.Optional,
+				},
+			})
+		}
+	}
+
+	return projections
+}
+
+// applyLoggingConfiguration configures the log level or mounts
+// a custom logback configuration.
+func applyLoggingConfiguration(logging *v1alpha1.Logging, m *manifest.Objects) error {
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		// A generated configuration would replace the custom one.
+		if logging.LogbackRef == nil {
+			if logging.Level != "" {
+				deployment.Spec.Template.Spec.Containers[0].Env = ensureEnvVar(deployment.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+					Name:  "LOGBACK_LEVEL",
+					Value: logging.Level,
+				})
+			}
+
+			return nil
+		}
+
+		ensureVolume(deployment, corev1.Volume{
+			Name: logbackVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: logging.LogbackRef.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: logging.LogbackRef.Key, Path: logbackFile}},
+					Optional:             logging.LogbackRef.Optional,
+				},
+			},
+		})
+
+		ensureContainerVolumeMount(deployment, "guacamole", corev1.VolumeMount{
+			Name:      logbackVolumeName,
+			ReadOnly:  true,
+			MountPath: logbackMountPath,
+		})
+
+		return nil
+	})
+}
@@ -0,0 +1,47 @@
+package transformer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+func TestPropertiesProjections(t *testing.T) {
+	properties := []v1alpha1.PropertiesSource{
+		{
+			ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+				Key:                  "guacamole.properties",
+			},
+		},
+		{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+				Key:                  "ldap.properties",
+			},
+		},
+	}
+
+	got := propertiesProjections(properties)
+	want := []corev1.VolumeProjection{
+		{
+			ConfigMap: &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "settings"},
+				Items:                []corev1.KeyToPath{{Key: "guacamole.properties", Path: "000.properties"}},
+			},
+		},
+		{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"},
+				Items:                []corev1.KeyToPath{{Key: "ldap.properties", Path: "001.properties"}},
+			},
+		},
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,310 @@
+package transformer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+const (
+	recordingsClaimName  = "recordings"
+	recordingsVolumeName = "recordings"
+	recordingsMountPath  = "/var/lib/guacamole/recordings"
+
+	// Group shared by guacd and Guacamole to access recordings.
+	// Matches the group of the guacd user in the upstream image.
+	recordingsFSGroup int64 = 1000
+
+	// User of guacd in the upstream image. Retention runs as this user
+	// to be able to remove recordings written by guacd.
+	recordingsUser int64 = 1000
+
+	recordingRetentionName      = "recording-retention"
+	recordingRetentionImage     = "ghcr.io/guacamole-operator/recording-retention:1.0.0"
+	recordingRetentionMountPath = "/recordings"
+	recordingRetentionSchedule  = "0 * * * *"
+)
+
+// recordingClaimName returns the name of the claim holding recordings.
+func recordingClaimName(guac *v1alpha1.Guacamole) string {
+	if guac.Spec.Recording.ExistingClaim != "" {
+		return guac.Spec.Recording.ExistingClaim
+	}
+
+	// Name after instance name was added.
+	return recordingsClaimName + "-" + guac.Name
+}
+
+// applyRecordingClaim adds a claim for recordings to the manifest
+// unless an existing claim is used.
+func applyRecordingClaim(recording *v1alpha1.Recording, m *manifest.Objects) error {
+	if recording.ExistingClaim != "" {
+		return nil
+	}
+
+	size := resource.MustParse("10Gi")
+	if recording.Size != nil {
+		size = *recording.Size
+	}
+
+	accessModes := recording.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+	}
+
+	claim := corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: recordingsClaimName,
+			Labels: map[string]string{
+				nameLabel: GuacamoleDeploymentName,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      accessModes,
+			StorageClassName: recording.StorageClassName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&claim)
+	if err != nil {
+		return err
+	}
+
+	obj, err := manifest.NewObject(&unstructured.Unstructured{Object: u})
+	if err != nil {
+		return err
+	}
+
+	m.Items = append(m.Items, obj)
+
+	return nil
+}
+
+// applyRecordingVolume mounts the recordings claim into a container
+// of a deployment.
+func applyRecordingVolume(guac *v1alpha1.Guacamole, name, container string, m *manifest.Objects) error {
+	return updateDeployment(m, name, func(deployment *appsv1.Deployment) error {
+		ensureVolume(deployment, corev1.Volume{
+			Name: recordingsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: recordingClaimName(guac),
+				},
+			},
+		})
+
+		ensureContainerVolumeMount(deployment, container, corev1.VolumeMount{
+			Name:      recordingsVolumeName,
+			MountPath: recordingsMountPath,
+		})
+
+		// guacd creates recordings readable by its group only.
+		podSpec := &deployment.Spec.Template.Spec
+		if podSpec.SecurityContext == nil {
+			podSpec.SecurityContext = &corev1.PodSecurityContext{}
+		}
+
+		if podSpec.SecurityContext.FSGroup == nil {
+			podSpec.SecurityContext.FSGroup = ptr.To(recordingsFSGroup)
+		}
+
+		return nil
+	})
+}
+
+// applyRecordingConfiguration provisions recording storage for Guacamole
+// and enables the history recording storage extension.
+func applyRecordingConfiguration(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	if err := applyRecordingClaim(guac.Spec.Recording, m); err != nil {
+		return err
+	}
+
+	if err := applyRecordingVolume(guac, GuacamoleDeploymentName, "guacamole", m); err != nil {
+		return err
+	}
+
+	if err := applyRecordingRetention(guac, m); err != nil {
+		return err
+	}
+
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		deployment.Spec.Template.Spec.Containers[0].Env = ensureEnvVar(
+			deployment.Spec.Template.Spec.Containers[0].Env,
+			corev1.EnvVar{
+				Name:  "RECORDING_SEARCH_PATH",
+				Value: recordingsMountPath,
+			},
+		)
+
+		return nil
+	})
+}
+
+// applyRecordingRetention adds a CronJob enforcing the retention
+// policy of recordings to the manifest.
+func applyRecordingRetention(guac *v1alpha1.Guacamole, m *manifest.Objects) error {
+	retention := guac.Spec.Recording.Retention
+	if retention == nil {
+		return nil
+	}
+
+	schedule := retention.Schedule
+	if schedule == "" {
+		schedule = recordingRetentionSchedule
+	}
+
+	container := corev1.Container{
+		Name:  recordingRetentionName,
+		Image: imageOrDefault(images(guac).RecordingRetention, recordingRetentionImage),
+		Args:  recordingRetentionArgs(retention),
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      recordingsVolumeName,
+				MountPath: recordingRetentionMountPath,
+			},
+		},
+	}
+
+	if archive := retention.Archive; archive != nil && archive.S3 != nil {
+		container.Env = append(container.Env, s3EnvVars(archive.S3)...)
+	}
+
+	// Apply cluster proxy.
+	for _, v := range getProxyVariables() {
+		if v.Value == "" {
+			continue
+		}
+
+		container.Env = append(container.Env, v)
+	}
+
+	cronJob := batchv1.CronJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "CronJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: recordingRetentionName,
+			Labels: map[string]string{
+				nameLabel: recordingRetentionName,
+			},
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								nameLabel: recordingRetentionName,
+							},
+						},
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyOnFailure,
+							SecurityContext: &corev1.PodSecurityContext{
+								RunAsUser:    ptr.To(recordingsUser),
+								RunAsGroup:   ptr.To(recordingsFSGroup),
+								RunAsNonRoot: ptr.To(true),
+								FSGroup:      ptr.To(recordingsFSGroup),
+							},
+							Containers: []corev1.Container{container},
+							Volumes: []corev1.Volume{
+								{
+									Name: recordingsVolumeName,
+									VolumeSource: corev1.VolumeSource{
+										PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+											ClaimName: recordingClaimName(guac),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&cronJob)
+	if err != nil {
+		return err
+	}
+
+	obj, err := manifest.NewObject(&unstructured.Unstructured{Object: u})
+	if err != nil {
+		return err
+	}
+
+	m.Items = append(m.Items, obj)
+
+	return nil
+}
+
+// recordingRetentionArgs returns the arguments of the retention
+// container for a retention policy.
+func recordingRetentionArgs(retention *v1alpha1.RecordingRetention) []string {
+	args := []string{"-dir", recordingRetentionMountPath}
+
+	if retention.MaxAge != nil {
+		args = append(args, "-max-age", retention.MaxAge.Duration.String())
+	}
+
+	if retention.MaxSize != nil {
+		args = append(args, "-max-size", strconv.FormatInt(retention.MaxSize.Value(), 10))
+	}
+
+	if len(retention.Overrides) > 0 {
+		overrides := make([]string, 0, len(retention.Overrides))
+		for _, o := range retention.Overrides {
+			overrides = append(overrides, fmt.Sprintf("%s=%s", o.Connection, o.MaxAge.Duration))
+		}
+
+		args = append(args, "-overrides", strings.Join(overrides, ","))
+	}
+
+	return args
+}
+
+// s3EnvVars returns the environment variables configuring
+// the archive of the retention container.
+func s3EnvVars(s3 *v1alpha1.S3Archive) []corev1.EnvVar {
+	secretKey := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: s3.CredentialsSecretRef,
+				Key:                  key,
+			},
+		}
+	}
+
+	return []corev1.EnvVar{
+		{Name: "S3_ENDPOINT", Value: s3.Endpoint},
+		{Name: "S3_BUCKET", Value: s3.Bucket},
+		{Name: "S3_REGION", Value: s3.Region},
+		{Name: "S3_PREFIX", Value: s3.Prefix},
+		{Name: "S3_ACCESS_KEY_ID", ValueFrom: secretKey("access-key-id")},
+		{Name: "S3_SECRET_ACCESS_KEY", ValueFrom: secretKey("secret-access-key")},
+	}
+}
@@ -0,0 +1,36 @@
+package transformer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+func TestRecordingRetentionArgs(t *testing.T) {
+	retention := &v1alpha1.RecordingRetention{
+		MaxAge:  &metav1.Duration{Duration: 30 * 24 * time.Hour},
+		MaxSize: ptr.To(resource.MustParse("1Gi")),
+		Overrides: []v1alpha1.RecordingRetentionOverride{
+			{Connection: "audit", MaxAge: metav1.Duration{Duration: 365 * 24 * time.Hour}},
+			{Connection: "lab", MaxAge: metav1.Duration{Duration: time.Hour}},
+		},
+	}
+
+	got := recordingRetentionArgs(retention)
+	want := []string{
+		"-dir", "/recordings",
+		"-max-age", "720h0m0s",
+		"-max-size", "1073741824",
+		"-overrides", "audit=8760h0m0s,lab=1h0m0s",
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected diff (-want +got):\n%s", cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,289 @@
+package transformer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+const (
+	schemaUpgradeName       = "schema-upgrade"
+	schemaUpgradeVolumeName = "upgrade"
+	schemaUpgradeBackoff    = 2
+)
+
+// applySchemaUpgrade tracks the version of the database schema. If the
+// Guacamole version changes, the matching upgrade scripts are applied by
+// a one-shot job and the web application is kept at the previous version
+// until the job completed.
+func applySchemaUpgrade(ctx context.Context, c client.Client, guac *v1alpha1.Guacamole, db *database, m *manifest.Objects) error {
+	image, err := guacamoleImage(m)
+	if err != nil {
+		return err
+	}
+
+	// Schema versions are only tracked for versioned images.
+	to := imageTag(image)
+	toVersion, err := version.ParseGeneric(to)
+	if err != nil {
+		return nil
+	}
+
+	from := guac.Status.SchemaVersion
+	if from == "" {
+		from = guac.GetAnnotations()[v1alpha1.SchemaVersionAnnotation]
+	}
+
+	// Instances deployed before the version was tracked run the schema
+	// of their deployed image.
+	if from == "" {
+		deployed, err := deployedImage(ctx, c, guac)
+		if err != nil {
+			return err
+		}
+
+		if deployed != "" {
+			from = imageTag(deployed)
+			if _, err := version.ParseGeneric(from); err != nil {
+				guac.Status.MarkSchemaVersionUnknown(deployed)
+				return pinGuacamoleImage(image, deployed, m)
+			}
+		}
+	}
+
+	// New databases are initialized with the current version.
+	if from == "" {
+		guac.Status.MarkSchemaUpToDate(to)
+		return nil
+	}
+
+	fromVersion, err := version.ParseGeneric(from)
+	if err != nil {
+		return fmt.Errorf("error parsing schema version %q: %w", from, err)
+	}
+
+	switch {
+	case !fromVersion.LessThan(toVersion) && !toVersion.LessThan(fromVersion):
+		guac.Status.MarkSchemaUpToDate(to)
+		return nil
+	case toVersion.LessThan(fromVersion):
+		guac.Status.MarkSchemaDowngrade(from, to)
+		return pinGuacamoleImage(image, imageWithTag(image, from), m)
+	}
+
+	name := schemaUpgradeName + "-" + strings.ReplaceAll(to, ".", "-")
+
+	var job batchv1.Job
+	err = c.Get(ctx, types.NamespacedName{Name: name + "-" + guac.Name, Namespace: guac.Namespace}, &job)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("error getting schema upgrade job: %w", err)
+	}
+
+	switch {
+	case err == nil && jobCondition(&job, batchv1.JobComplete):
+		// Job is pruned after the schema version was updated.
+		guac.Status.MarkSchemaUpToDate(to)
+		return nil
+	case err == nil && jobCondition(&job, batchv1.JobFailed):
+		// Failed job is kept for inspection.
+		guac.Status.MarkSchemaUpgradeFailed(from, to, job.Name)
+	default:
+		guac.Status.MarkSchemaUpgrading(from, to)
+	}
+
+	if err := applySchemaUpgradeJob(db, name, image, from, to, m); err != nil {
+		return err
+	}
+
+	return pinGuacamoleImage(image, imageWithTag(image, from), m)
+}
+
+// applySchemaUpgradeJob adds the job upgrading the database schema
+// to the manifest.
+func applySchemaUpgradeJob(db *database, name, image, from, to string, m *manifest.Objects) error {
+	mounts := []corev1.VolumeMount{
+		{
+			Name:      schemaUpgradeVolumeName,
+			MountPath: "/data",
+		},
+	}
+
+	job := batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "batch/v1",
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				nameLabel: schemaUpgradeName,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To[int32](schemaUpgradeBackoff),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						nameLabel: schemaUpgradeName,
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					InitContainers: []corev1.Container{
+						{
+							Name:         "select-upgrade-scripts",
+							Image:        image,
+							Command:      []string{"/bin/sh"},
+							Args:         []string{"-c", selectUpgradeScripts},
+							VolumeMounts: mounts,
+							Env: []corev1.EnvVar{
+								{Name: "DATABASE", Value: db.name},
+								{Name: "FROM_VERSION", Value: from},
+								{Name: "TO_VERSION", Value: to},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:         "apply-upgrade-scripts",
+							Image:        db.image,
+							Command:      []string{"/bin/sh"},
+							Args:         []string{"-c", db.upgradeScript},
+							VolumeMounts: mounts,
+							Env:          envVarFromParameters(db.params),
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: schemaUpgradeVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&job)
+	if err != nil {
+		return err
+	}
+
+	obj, err := manifest.NewObject(&unstructured.Unstructured{Object: u})
+	if err != nil {
+		return err
+	}
+
+	m.Items = append(m.Items, obj)
+
+	return nil
+}
+
+// guacamoleImage returns the image of the Guacamole container.
+func guacamoleImage(m *manifest.Objects) (string, error) {
+	var image string
+
+	err := updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		image = deployment.Spec.Template.Spec.Containers[0].Image
+		return nil
+	})
+
+	return image, err
+}
+
+// deployedImage returns the image of the Guacamole container of the
+// deployed instance or an empty string if it is not deployed.
+func deployedImage(ctx context.Context, c client.Client, guac *v1alpha1.Guacamole) (string, error) {
+	var deployment appsv1.Deployment
+
+	key := types.NamespacedName{Name: GuacamoleDeploymentName + "-" + guac.Name, Namespace: guac.Namespace}
+	if err := c.Get(ctx, key, &deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("error getting Guacamole deployment: %w", err)
+	}
+
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == GuacamoleDeploymentName {
+			return container.Image, nil
+		}
+	}
+
+	return "", nil
+}
+
+// pinGuacamoleImage replaces the image of all containers of the
+// Guacamole deployment using the given image.
+func pinGuacamoleImage(image, pinned string, m *manifest.Objects) error {
+	return updateDeployment(m, GuacamoleDeploymentName, func(deployment *appsv1.Deployment) error {
+		podSpec := &deployment.Spec.Template.Spec
+
+		for i := range podSpec.InitContainers {
+			if podSpec.InitContainers[i].Image == image {
+				podSpec.InitContainers[i].Image = pinned
+			}
+		}
+
+		for i := range podSpec.Containers {
+			if podSpec.Containers[i].Image == image {
+				podSpec.Containers[i].Image = pinned
+			}
+		}
+
+		return nil
+	})
+}
+
+// imageTag returns the tag of an image reference or an empty
+// string if the reference has no tag.
+func imageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+
+	idx := strings.LastIndex(image, ":")
+	if idx <= strings.LastIndex(image, "/") {
+		return ""
+	}
+
+	return image[idx+1:]
+}
+
+// imageWithTag replaces the tag of an image reference.
+func imageWithTag(image, tag string) string {
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		image = image[:idx]
+	}
+
+	return image + ":" + tag
+}
+
+// jobCondition returns whether a job condition is true.
+func jobCondition(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == conditionType && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,185 @@
+package transformer
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
+
+	"github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
+)
+
+func TestImageTag(t *testing.T) {
+	tests := []struct {
+		image string
+		tag   string
+		with  string
+	}{
+		{"docker.io/guacamole/guacamole:1.6.0", "1.6.0", "docker.io/guacamole/guacamole:1.5.5"},
+		{"registry:5000/guacamole:1.6.0", "1.6.0", "registry:5000/guacamole:1.5.5"},
+		{"registry:5000/guacamole", "", "registry:5000/guacamole:1.5.5"},
+		{"guacamole", "", "guacamole:1.5.5"},
+		{"guacamole@sha256:abc", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := imageTag(tt.image); got != tt.tag {
+			t.Errorf("imageTag(%q) = %q, want %q", tt.image, got, tt.tag)
+		}
+
+		if tt.with == "" {
+			continue
+		}
+
+		if got := imageWithTag(tt.image, "1.5.5"); got != tt.with {
+			t.Errorf("imageWithTag(%q) = %q, want %q", tt.image, got, tt.with)
+		}
+	}
+}
+
+func TestApplySchemaUpgrade(t *testing.T) {
+	deployed := func(image string) client.Object {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "guacamole-example", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "guacamole", Image: image}},
+			}}},
+		}
+	}
+
+	job := func(condition batchv1.JobConditionType) client.Object {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "schema-upgrade-1-6-0-example", Namespace: "default"},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: condition, Status: corev1.ConditionTrue},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		schemaVersion string
+		objects       []client.Object
+		reason        v1alpha1.GuacamoleConditionReason
+		image         string
+		job           bool
+	}{
+		{
+			name:   "new instance",
+			reason: v1alpha1.GuacamoleSchemaUpToDate,
+			image:  "docker.io/guacamole/guacamole:1.6.0",
+		},
+		{
+			name:          "up to date",
+			schemaVersion: "1.6.0",
+			reason:        v1alpha1.GuacamoleSchemaUpToDate,
+			image:         "docker.io/guacamole/guacamole:1.6.0",
+		},
+		{
+			name:          "upgrade",
+			schemaVersion: "1.5.5",
+			reason:        v1alpha1.GuacamoleSchemaUpgrading,
+			image:         "docker.io/guacamole/guacamole:1.5.5",
+			job:           true,
+		},
+		{
+			name:          "upgrade completed",
+			schemaVersion: "1.5.5",
+			objects:       []client.Object{job(batchv1.JobComplete)},
+			reason:        v1alpha1.GuacamoleSchemaUpToDate,
+			image:         "docker.io/guacamole/guacamole:1.6.0",
+		},
+		{
+			name:          "upgrade failed",
+			schemaVersion: "1.5.5",
+			objects:       []client.Object{job(batchv1.JobFailed)},
+			reason:        v1alpha1.GuacamoleSchemaUpgradeFailed,
+			image:         "docker.io/guacamole/guacamole:1.5.5",
+			job:           true,
+		},
+		{
+			name:          "downgrade",
+			schemaVersion: "1.6.1",
+			reason:        v1alpha1.GuacamoleSchemaDowngrade,
+			image:         "docker.io/guacamole/guacamole:1.6.1",
+		},
+		{
+			// Instances deployed before versions were tracked.
+			name:    "untracked deployed version",
+			objects: []client.Object{deployed("docker.io/guacamole/guacamole:1.5.5")},
+			reason:  v1alpha1.GuacamoleSchemaUpgrading,
+			image:   "docker.io/guacamole/guacamole:1.5.5",
+			job:     true,
+		},
+		{
+			name:    "unknown deployed version",
+			objects: []client.Object{deployed("docker.io/guacamole/guacamole:latest")},
+			reason:  v1alpha1.GuacamoleSchemaVersionUnknown,
+			image:   "docker.io/guacamole/guacamole:latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			m, err := manifest.ParseObjects(ctx, `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: guacamole
+spec:
+  template:
+    spec:
+      containers:
+        - name: guacamole
+          image: docker.io/guacamole/guacamole:1.6.0
+`)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			guac := &v1alpha1.Guacamole{
+				ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+				Status:     v1alpha1.GuacamoleStatus{SchemaVersion: tt.schemaVersion},
+			}
+
+			db := databaseFor(&v1alpha1.Auth{Postgres: &v1alpha1.Postgres{}})
+			c := fake.NewClientBuilder().WithObjects(tt.objects...).Build()
+
+			if err := applySchemaUpgrade(ctx, c, guac, db, m); err != nil {
+				t.Fatal(err)
+			}
+
+			condition := meta.FindStatusCondition(guac.Status.Conditions, string(v1alpha1.GuacamoleSchemaReady))
+			if condition == nil || condition.Reason != string(tt.reason) {
+				t.Errorf("expected reason %s, got condition %+v", tt.reason, condition)
+			}
+
+			image, err := guacamoleImage(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if image != tt.image {
+				t.Errorf("expected image %s, got %s", tt.image, image)
+			}
+
+			hasJob := false
+			for _, item := range m.Items {
+				hasJob = hasJob || item.Kind == "Job"
+			}
+
+			if hasJob != tt.job {
+				t.Errorf("expected upgrade job %t, got %t", tt.job, hasJob)
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package transformer
 
 import (
+	"fmt"
 	"os"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/declarative/pkg/manifest"
 )
 
@@ -11,6 +15,97 @@ func isDeployment(obj *manifest.Object) bool {
 	return obj.Kind == "Deployment"
 }
 
+func isService(obj *manifest.Object) bool {
+	return obj.Kind == "Service"
+}
+
+// updateDeployment converts the deployment with the given name to its typed
+// representation, applies fn and writes the result back to the manifest.
+func updateDeployment(m *manifest.Objects, name string, fn func(*appsv1.Deployment) error) error {
+	for idx, item := range m.Items {
+		if !isDeployment(item) || item.GetName() != name {
+			continue
+		}
+
+		var deployment appsv1.Deployment
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredObject().Object, &deployment)
+		if err != nil {
+			return fmt.Errorf("error converting deployment from unstructured: %w", err)
+		}
+
+		if err := fn(&deployment); err != nil {
+			return err
+		}
+
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&deployment)
+		if err != nil {
+			return err
+		}
+
+		obj, err := manifest.NewObject(&unstructured.Unstructured{Object: u})
+		if err != nil {
+			return err
+		}
+
+		m.Items[idx] = obj
+
+		break
+	}
+
+	return nil
+}
+
+// updateService converts the service with the given name to its typed
+// representation, applies fn and writes the result back to the manifest.
+func updateService(m *manifest.Objects, name string, fn func(*corev1.Service) error) error {
+	for idx, item := range m.Items {
+		if !isService(item) || item.GetName() != name {
+			continue
+		}
+
+		var service corev1.Service
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.UnstructuredObject().Object, &service)
+		if err != nil {
+			return fmt.Errorf("error converting service from unstructured: %w", err)
+		}
+
+		if err := fn(&service); err != nil {
+			return err
+		}
+
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&service)
+		if err != nil {
+			return err
+		}
+
+		obj, err := manifest.NewObject(&unstructured.Unstructured{Object: u})
+		if err != nil {
+			return err
+		}
+
+		m.Items[idx] = obj
+
+		break
+	}
+
+	return nil
+}
+
+// setPodAnnotation sets an annotation on a pod template. Empty values
+// remove the annotation.
+func setPodAnnotation(template *corev1.PodTemplateSpec, key, value string) {
+	if value == "" {
+		delete(template.Annotations, key)
+		return
+	}
+
+	if template.Annotations == nil {
+		template.Annotations = make(map[string]string)
+	}
+
+	template.Annotations[key] = value
+}
+
 func getProxyVariables() []corev1.EnvVar {
 	proxyEnv := []corev1.EnvVar{{
 		Name:  "HTTPS_PROXY",
@@ -32,14 +32,19 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/kubebuilder-declarative-pattern/pkg/patterns/addon/pkg/loaders"
 
 	"github.com/go-logr/logr"
 
 	v1alpha1 "github.com/guacamole-operator/guacamole-operator/api/v1alpha1"
 	"github.com/guacamole-operator/guacamole-operator/controllers"
+	"github.com/guacamole-operator/guacamole-operator/internal/channels"
 	"github.com/guacamole-operator/guacamole-operator/internal/config"
+	"github.com/guacamole-operator/guacamole-operator/internal/credentials"
+	"github.com/guacamole-operator/guacamole-operator/internal/jsonauth"
 	"github.com/guacamole-operator/guacamole-operator/internal/listener"
 	//+kubebuilder:scaffold:imports
 )
@@ -64,6 +69,11 @@ func main() {
 	var guacConcurrency int
 	var enableGuacEventListener bool
 	var usePriorityQueue bool
+	var jsonAuthAddr string
+	var jsonAuthCertDir string
+	var channelsLocation string
+	var channelsConfigMap string
+	var registryMirror string
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address",
 		config.EnvOrDefault("METRICS_BIND_ADDRESS", ":8080"),
@@ -96,8 +106,45 @@ func main() {
 		config.EnvBoolOrDefault("PRIORITY_QUEUE", false),
 		"Use controller-runtime's priority queue implementation.")
 
+	flag.StringVar(&jsonAuthAddr, "json-auth-bind-address",
+		config.EnvOrDefault("JSON_AUTH_BIND_ADDRESS", "0"),
+		"The address the JSON auth endpoint binds to. Clients authenticate with "+
+			"Kubernetes bearer tokens and need permission to create connections/token. "+
+			"Set to 0 to disable.")
+
+	flag.StringVar(&jsonAuthCertDir, "json-auth-cert-dir",
+		config.EnvOrDefault("JSON_AUTH_CERT_DIR", ""),
+		"Directory containing tls.crt and tls.key of the JSON auth endpoint. "+
+			"Without, the endpoint only binds to loopback addresses.")
+
+	flag.StringVar(&channelsLocation, "channels-location",
+		config.EnvOrDefault("CHANNELS_LOCATION", ""),
+		"Location of the channel packages, a directory or an HTTP(S) URL. "+
+			"Defaults to the packages bundled into the binary.")
+
+	flag.StringVar(&channelsConfigMap, "channels-configmap",
+		config.EnvOrDefault("CHANNELS_CONFIGMAP", ""),
+		"ConfigMap (namespace/name) containing the channel packages. "+
+			"Keys are the paths of the files with separator '_'.")
+
+	flag.StringVar(&registryMirror, "registry-mirror",
+		config.EnvOrDefault("REGISTRY_MIRROR", ""),
+		"Registry (and path) all images of instances are rewritten to, "+
+			"e.g. registry.example.com/mirror.")
+
+	// Registered by the addon loaders, kept as alias of --channels-location.
+	if f := flag.Lookup("channel"); f != nil {
+		f.Usage = "Deprecated: use --channels-location."
+	}
+
 	flag.Parse()
 
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "channel" && channelsLocation == "" {
+			channelsLocation = loaders.FlagChannel
+		}
+	})
+
 	// Configure logging.
 	opts := slog.HandlerOptions{
 		AddSource: true,
@@ -148,17 +195,29 @@ func main() {
 		os.Exit(1)
 	}
 
+	// The ConfigMap is read uncached, it may be outside of the watch namespace.
+	channelsRepository, err := channels.NewRepository(mgr.GetAPIReader(), channelsLocation, channelsConfigMap)
+	if err != nil {
+		setupLog.Error(err, "unable to set up channels")
+		os.Exit(1)
+	}
+
 	// Setup reconcilers.
 	if err = (&controllers.GuacamoleReconciler{
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
 		EnableListener: enableGuacEventListener,
 		Listener:       eventListener,
+		Channels:       channelsRepository,
+		RegistryMirror: registryMirror,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Guacamole")
 		os.Exit(1)
 	}
 
+	// Shared by the reconciler and the JSON auth endpoint to reuse cached secrets.
+	credentialsResolver := &credentials.Resolver{Client: mgr.GetClient()}
+
 	if err = (&controllers.ConnectionReconciler{
 		Client:               mgr.GetClient(),
 		Scheme:               mgr.GetScheme(),
@@ -166,12 +225,37 @@ func main() {
 		GuacConcurrency:      guacConcurrency,
 		UsePriorityQueue:     usePriorityQueue,
 		GuacEventCh:          triggerCh,
+		Credentials:          credentialsResolver,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Connection")
 		os.Exit(1)
 	}
 	//+kubebuilder:scaffold:builder
 
+	if jsonAuthAddr != "0" {
+		if err := mgr.Add(&jsonauth.Server{
+			Addr:        jsonAuthAddr,
+			CertDir:     jsonAuthCertDir,
+			Client:      mgr.GetClient(),
+			Credentials: credentialsResolver,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up JSON auth endpoint")
+			os.Exit(1)
+		}
+	}
+
+	// Gauges of active connections of instances with metrics enabled.
+	metricsCollector := &controllers.MetricsCollector{Client: mgr.GetClient()}
+	if err := ctrlmetrics.Registry.Register(metricsCollector); err != nil {
+		setupLog.Error(err, "unable to register metrics collector")
+		os.Exit(1)
+	}
+
+	if err := mgr.Add(metricsCollector); err != nil {
+		setupLog.Error(err, "unable to set up metrics collector")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)